@@ -0,0 +1,161 @@
+// Package dump renders the messages of the wire as JSON for the command-line tools
+package dump
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// maxDepth bounds the containers which are nested in the containers
+const maxDepth = 8
+
+// Envelope returns the envelope with its message decoded by the constructor, the containers are
+// decoded recursively
+func Envelope(env *msg.MessageEnvelope) map[string]interface{} {
+	return envelope(env, 0)
+}
+
+// Message returns the message of the constructor decoded, or base64 if it could not be decoded
+func Message(constructor int64, data []byte) interface{} {
+	return message(constructor, data, 0)
+}
+
+// JSON returns v as indented JSON
+func JSON(v interface{}) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func envelope(env *msg.MessageEnvelope, depth int) map[string]interface{} {
+	out := map[string]interface{}{
+		"Constructor": msg.ConstructorName(env.Constructor),
+		"RequestID":   strconv.FormatUint(env.RequestID, 10),
+	}
+	if len(env.Header) > 0 {
+		header := make(map[string]string, len(env.Header))
+		for _, kv := range env.Header {
+			header[kv.Key] = kv.Value
+		}
+		out["Header"] = header
+	}
+	if len(env.Auth) > 0 {
+		out["Auth"] = env.Auth
+	}
+	out["Message"] = message(env.Constructor, env.Message, depth)
+	return out
+}
+
+func message(constructor int64, data []byte, depth int) interface{} {
+	m, ok := msg.NewMessage(constructor)
+	if !ok {
+		if len(data) == 0 {
+			return nil
+		}
+		return data
+	}
+	if depth >= maxDepth {
+		return map[string]interface{}{"Error": "too deeply nested", "Raw": data}
+	}
+	if err := m.Unmarshal(data); err != nil {
+		return map[string]interface{}{"Error": err.Error(), "Raw": data}
+	}
+
+	switch x := m.(type) {
+	case *msg.MessageContainer:
+		envelopes := make([]interface{}, 0, len(x.Envelopes))
+		for _, env := range x.Envelopes {
+			envelopes = append(envelopes, envelope(env, depth+1))
+		}
+		return map[string]interface{}{
+			"Length":    x.Length,
+			"Envelopes": envelopes,
+		}
+	case *msg.UpdateContainer:
+		out := fields(x)
+		updates := make([]interface{}, 0, len(x.Updates))
+		for _, u := range x.Updates {
+			update := fields(u)
+			update["Constructor"] = msg.ConstructorName(u.Constructor)
+			update["Update"] = message(u.Constructor, u.Update, depth+1)
+			updates = append(updates, update)
+		}
+		out["Updates"] = updates
+		return out
+	case *msg.MessageEnvelope:
+		return envelope(x, depth+1)
+	}
+	return m
+}
+
+// fields returns the exported fields of the struct, so some of them could be replaced by their decoded values
+func fields(v interface{}) map[string]interface{} {
+	rv := reflect.ValueOf(v).Elem()
+	out := make(map[string]interface{}, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		if f := rv.Type().Field(i); f.PkgPath == "" {
+			out[f.Name] = rv.Field(i).Interface()
+		}
+	}
+	return out
+}
+
+// KeyFunc returns the auth key of the auth id, or nil if it is unknown
+type KeyFunc func(authID int64) []byte
+
+// Frame returns the ProtoMessage frame decoded. The encrypted frames are decrypted by the auth key which
+// keys returns, otherwise only their plain fields are returned.
+func Frame(frame []byte, keys KeyFunc) (map[string]interface{}, error) {
+	pm := &msg.ProtoMessage{}
+	if err := pm.Unmarshal(frame); err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{
+		"AuthID":     strconv.FormatInt(pm.AuthID, 10),
+		"MessageKey": hex.EncodeToString(pm.MessageKey),
+	}
+	if pm.AuthID == 0 {
+		env := &msg.MessageEnvelope{}
+		if err := env.Unmarshal(pm.Payload); err != nil {
+			return nil, err
+		}
+		out["Envelope"] = Envelope(env)
+		return out, nil
+	}
+
+	var authKey []byte
+	if keys != nil {
+		authKey = keys(pm.AuthID)
+	}
+	if authKey == nil {
+		out["Encrypted"] = true
+		out["PayloadSize"] = len(pm.Payload)
+		return out, nil
+	}
+	plain, err := utils.Decrypt(authKey, pm.MessageKey, pm.Payload)
+	if err != nil {
+		return nil, err
+	}
+	payload := &msg.ProtoEncryptedPayload{}
+	if err = payload.Unmarshal(plain); err != nil {
+		return nil, err
+	}
+	out["ServerSalt"] = strconv.FormatInt(payload.ServerSalt, 10)
+	out["SessionID"] = strconv.FormatInt(payload.SessionID, 10)
+	out["MessageID"] = strconv.FormatUint(payload.MessageID, 10)
+	// the high 32 bits of the message id are the time it is sent in seconds, the low bits are its sequence
+	out["MessageTime"] = time.Unix(int64(payload.MessageID>>32), 0).UTC().Format(time.RFC3339)
+	out["MessageSeq"] = payload.MessageID & 0xFFFFFFFF
+	if payload.Envelope != nil {
+		out["Envelope"] = Envelope(payload.Envelope)
+	}
+	return out, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"git.ronaksoft.com/river/web-wasm/cmd/internal/dump"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/river"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/net/websocket"
+)
+
+// handshakeID is the id of the handshake of riverctl, it runs one handshake at a time
+const handshakeID = 1
+
+// client
+// Sends the envelopes of River over WebSocket and waits for their responses, the envelopes which
+// are not the response of a request are printed as updates
+type client struct {
+	r         *river.River
+	ws        *websocket.Conn
+	clusterID int32
+	out       io.Writer
+
+	mtx     sync.Mutex
+	waiting map[uint64]chan *msg.MessageEnvelope
+	closed  chan struct{}
+	err     error
+
+	// timeSyncing is 1 while SystemGetServerTime is in flight, a single time sync runs at a time
+	timeSyncing int32
+}
+
+func dial(addr string, r *river.River, clusterID int32, out io.Writer) (*client, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	origin := "http://" + u.Host
+	if u.Scheme == "wss" {
+		origin = "https://" + u.Host
+	}
+	ws, err := websocket.Dial(addr, "", origin)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	c := &client{
+		r:         r,
+		ws:        ws,
+		clusterID: clusterID,
+		out:       out,
+		waiting:   make(map[uint64]chan *msg.MessageEnvelope),
+		closed:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *client) Close() error {
+	return c.ws.Close()
+}
+
+// Closed is closed when the connection is lost
+func (c *client) Closed() <-chan struct{} {
+	return c.closed
+}
+
+func (c *client) readLoop() {
+	defer close(c.closed)
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(c.ws, &frame); err != nil {
+			c.mtx.Lock()
+			c.err = err
+			c.mtx.Unlock()
+			return
+		}
+		env, err := c.r.Decode(frame)
+		if err != nil || env == nil {
+			fmt.Fprintln(c.out, "decode error:", err)
+			continue
+		}
+		// the skew which is found while SystemGetServerTime is in flight is fixed by its reply
+		if c.r.NeedsTimeSync() && atomic.CompareAndSwapInt32(&c.timeSyncing, 0, 1) {
+			go c.syncTime()
+		}
+		c.dispatch(env)
+	}
+}
+
+// syncTime sends SystemGetServerTime, its reply is a time sample of the clock
+func (c *client) syncTime() {
+	defer atomic.StoreInt32(&c.timeSyncing, 0)
+	_, _ = c.call(msg.C_SystemGetServerTime, nil, requestTimeout)
+}
+
+// dispatch delivers the responses to their requests by the same dispatch as parseEnvelope in the browser
+func (c *client) dispatch(env *msg.MessageEnvelope) {
+	if err := c.r.Dispatch(env, c); err != nil {
+		fmt.Fprintln(c.out, "decode error:", err)
+	}
+}
+
+func (c *client) OnUpdate(data []byte) {
+	c.printUpdate(&msg.MessageEnvelope{Constructor: msg.C_UpdateContainer, Message: data})
+}
+
+func (c *client) OnMessage(requestID uint64, constructor int64, data []byte) {
+	env := &msg.MessageEnvelope{
+		Constructor: constructor,
+		RequestID:   requestID,
+		Message:     data,
+	}
+	c.mtx.Lock()
+	ch, ok := c.waiting[requestID]
+	delete(c.waiting, requestID)
+	c.mtx.Unlock()
+	if ok {
+		ch <- env
+		return
+	}
+	c.printUpdate(env)
+}
+
+func (c *client) printUpdate(env *msg.MessageEnvelope) {
+	fmt.Fprintln(c.out, "update:", dump.JSON(dump.Envelope(env)))
+}
+
+// call sends the request and waits for its response
+func (c *client) call(constructor int64, body []byte, timeout time.Duration) (*msg.MessageEnvelope, error) {
+	env := &msg.MessageEnvelope{
+		Constructor: constructor,
+		RequestID:   utils.RandomUint64(),
+		Message:     body,
+	}
+	ch := make(chan *msg.MessageEnvelope, 1)
+	c.mtx.Lock()
+	c.waiting[env.RequestID] = ch
+	c.mtx.Unlock()
+	defer func() {
+		c.mtx.Lock()
+		delete(c.waiting, env.RequestID)
+		c.mtx.Unlock()
+	}()
+
+	frame, err := c.r.EncodeFor(c.clusterID, env)
+	if err != nil {
+		return nil, err
+	}
+	if err = websocket.Message.Send(c.ws, frame); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-c.closed:
+		c.mtx.Lock()
+		err = c.err
+		c.mtx.Unlock()
+		return nil, err
+	case <-time.After(timeout):
+		return nil, errors.New("request timed out")
+	}
+}
+
+// expect returns the message of the response if it is of the constructor
+func expect(res *msg.MessageEnvelope, constructor int64) ([]byte, error) {
+	if res.Constructor == constructor {
+		return res.Message, nil
+	}
+	if res.Constructor == msg.C_Error {
+		x := &msg.Error{}
+		if err := x.Unmarshal(res.Message); err == nil {
+			return nil, fmt.Errorf("server error %s %s", x.Code, x.Items)
+		}
+	}
+	return nil, fmt.Errorf("unexpected response %s", msg.ConstructorName(res.Constructor))
+}
+
+// auth runs the handshake and keeps the auth key of the cluster
+func (c *client) auth(timeout time.Duration) error {
+	progress := func(int64) {}
+	res, err := c.call(msg.C_InitConnect, c.r.AuthStep1(handshakeID, c.clusterID, progress), timeout)
+	if err != nil {
+		return err
+	}
+	data, err := expect(res, msg.C_InitResponse)
+	if err != nil {
+		return err
+	}
+	req, err := c.r.AuthStep2(handshakeID, data, progress)
+	if err != nil {
+		return err
+	}
+	if res, err = c.call(msg.C_InitCompleteAuth, req, timeout); err != nil {
+		return err
+	}
+	if data, err = expect(res, msg.C_InitAuthCompleted); err != nil {
+		return err
+	}
+	if _, err = c.r.AuthStep3(handshakeID, data, progress); err != nil {
+		return err
+	}
+	if _, _, err = c.r.ConnInfo.GetClusterKey(c.clusterID); err != nil {
+		return _errors.ErrAuthFailed
+	}
+	return nil
+}
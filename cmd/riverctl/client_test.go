@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/river"
+)
+
+// waitFor polls cond until it is true or a few seconds passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSingleTimeSync skews the clock of the client for every pushed update while SystemGetServerTime
+// is held by the stub, a single time sync must be in flight
+func TestSingleTimeSync(t *testing.T) {
+	s := newTestStub(t)
+	var (
+		timeRequests int32
+		release      = make(chan struct{})
+	)
+	s.HandleFunc(func(authID int64, req *msg.MessageEnvelope) *msg.MessageEnvelope {
+		if req.Constructor != msg.C_SystemGetServerTime {
+			return req
+		}
+		atomic.AddInt32(&timeRequests, 1)
+		<-release
+		data, _ := (&msg.SystemServerTime{Timestamp: time.Now().Unix()}).Marshal()
+		return &msg.MessageEnvelope{Constructor: msg.C_SystemServerTime, Message: data}
+	})
+
+	r := river.NewRiver("timesync")
+	if err := r.Load("{}", s.ServerKeys()); err != _errors.ErrNoAuthKey {
+		t.Fatalf("Load: %v", err)
+	}
+	out := &syncBuffer{}
+	c, err := dial(s.addr, r, 0, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err = c.auth(requestTimeout); err != nil {
+		t.Fatal(err)
+	}
+	// the stub pushes to the connections which have sent an encrypted request
+	if _, err = c.call(msg.C_Error, nil, requestTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	// the local clock runs ahead of each update by more than MaxClockSkew, and it moves on
+	// more than the resync interval of the connection info between them, so each one asks for a sync
+	authID, _, _ := r.ConnInfo.GetClusterKey(0)
+	var skew int64
+	r.ConnInfo.SetLocalClock(func() int64 {
+		return time.Now().UnixNano()/int64(time.Millisecond) + atomic.LoadInt64(&skew)
+	})
+	const pushes = 5
+	for i := 1; i <= pushes; i++ {
+		atomic.StoreInt64(&skew, int64(i)*3600*1000)
+		updates, _ := (&msg.UpdateContainer{
+			Length:      1,
+			Updates:     []*msg.UpdateEnvelope{{UpdateID: int64(i)}},
+			MinUpdateID: int64(i),
+			MaxUpdateID: int64(i),
+		}).Marshal()
+		err = s.Push(authID, &msg.MessageEnvelope{Constructor: msg.C_UpdateContainer, Message: updates})
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, "the update", func() bool { return strings.Count(out.String(), "update:") == i })
+	}
+	waitFor(t, "SystemGetServerTime", func() bool { return atomic.LoadInt32(&timeRequests) == 1 })
+	close(release)
+
+	waitFor(t, "the time sync", func() bool {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		return len(c.waiting) == 0 && atomic.LoadInt32(&c.timeSyncing) == 0
+	})
+	if n := atomic.LoadInt32(&timeRequests); n != 1 {
+		t.Fatalf("%d time syncs are sent, expected 1", n)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"git.ronaksoft.com/river/web-wasm/cmd/internal/dump"
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// maxFrameSize bounds the lines of the frames which are read from stdin
+const maxFrameSize = 16 << 20
+
+// runInspect decodes the frames which are captured from the WebSocket inspector of the browser, the
+// encrypted frames are decrypted by the auth keys of the connection info or by -key
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("riverctl inspect", flag.ExitOnError)
+	connFile := fs.String("conn", "", "connection info file, e.g. exported from river.connInfo.<handle> of the browser")
+	key := fs.String("key", "", "base64 or hex auth key")
+	format := fs.String("format", "auto", "encoding of the frames: auto, base64 or hex")
+	_ = fs.Parse(args)
+
+	keys, err := inspectKeys(*connFile, *key)
+	if err != nil {
+		return err
+	}
+
+	// the frames are the arguments, or the lines of stdin
+	var frames []string
+	if fs.NArg() > 0 {
+		frames = fs.Args()
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 64*1024), maxFrameSize)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				frames = append(frames, line)
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, f := range frames {
+		if err = inspectFrame(os.Stdout, f, *format, keys); err != nil {
+			fmt.Fprintln(os.Stderr, "riverctl:", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d frames could not be decoded", failed, len(frames))
+	}
+	return nil
+}
+
+func inspectFrame(w io.Writer, text, format string, keys dump.KeyFunc) error {
+	frame, err := decodeFrame(text, format)
+	if err != nil {
+		return err
+	}
+	out, err := dump.Frame(frame, keys)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, dump.JSON(out))
+	return err
+}
+
+// decodeFrame accepts the frames in hex or in any of the base64 encodings. A text which is both hex and
+// base64 is decoded as the one which is a frame.
+func decodeFrame(text, format string) ([]byte, error) {
+	return decodeText("frame", text, format, func(b []byte) bool {
+		pm := &msg.ProtoMessage{}
+		return pm.Unmarshal(b) == nil && len(pm.Payload) > 0 && (pm.AuthID == 0 || len(pm.MessageKey) == 32)
+	})
+}
+
+// decodeText decodes the text of what in the format, the auto format prefers the decoding which is valid
+func decodeText(what, text, format string, valid func([]byte) bool) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if format != "auto" && format != "hex" && format != "base64" {
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+	var decoded [][]byte
+	if format != "base64" {
+		b, err := hex.DecodeString(text)
+		if format == "hex" {
+			return b, err
+		}
+		if err == nil {
+			decoded = append(decoded, b)
+		}
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(text); err == nil {
+			decoded = append(decoded, b)
+			break
+		}
+	}
+	if len(decoded) == 0 {
+		return nil, errors.New(what + " is neither base64 nor hex")
+	}
+	for _, b := range decoded {
+		if valid(b) {
+			return b, nil
+		}
+	}
+	return decoded[0], nil
+}
+
+// inspectKeys returns the auth keys of the connection info, and the key of -key for any auth id
+func inspectKeys(connFile, key string) (dump.KeyFunc, error) {
+	var (
+		conn    *river_conn.RiverConnection
+		authKey []byte
+		err     error
+	)
+	if connFile != "" {
+		data, err := ioutil.ReadFile(connFile)
+		if err != nil {
+			return nil, err
+		}
+		if conn, err = river_conn.NewRiverConnection(handle, string(data)); err != nil {
+			return nil, err
+		}
+	}
+	if key != "" {
+		authKey, err = decodeText("auth key", key, "auto", func(b []byte) bool { return len(b) == 256 })
+		if err != nil {
+			return nil, err
+		}
+		if len(authKey) != 256 {
+			return nil, errors.New("auth key must be 256 bytes")
+		}
+	}
+
+	return func(authID int64) []byte {
+		if conn != nil {
+			if k, err := conn.GetKeyByAuthID(authID); err == nil {
+				return k
+			}
+		}
+		return authKey
+	}, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// testFrame returns an unencrypted frame of SystemGetServerTime
+func testFrame(t *testing.T) []byte {
+	env, err := (&msg.MessageEnvelope{Constructor: msg.C_SystemGetServerTime, RequestID: 42}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := (&msg.ProtoMessage{Payload: env}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+// sealFrame returns the frame of an Error which Items are items, encrypted by the auth key
+func sealFrame(t *testing.T, authID int64, authKey []byte, items string) []byte {
+	data, _ := (&msg.Error{Code: "E00", Items: items}).Marshal()
+	plain, err := (&msg.ProtoEncryptedPayload{
+		ServerSalt: 1,
+		SessionID:  2,
+		MessageID:  1700000000<<32 | 3,
+		Envelope:   &msg.MessageEnvelope{Constructor: msg.C_Error, RequestID: 4, Message: data},
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageKey := utils.GenerateMessageKey(authKey, plain)
+	encrypted, err := utils.Encrypt(authKey, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := (&msg.ProtoMessage{AuthID: authID, MessageKey: messageKey, Payload: encrypted}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+func testInspectKey(seed byte) []byte {
+	k := make([]byte, 256)
+	for i := range k {
+		k[i] = seed + byte(i)
+	}
+	return k
+}
+
+func TestDecodeFrame(t *testing.T) {
+	frame := testFrame(t)
+	// the base64 of this frame is only made of hex digits, its hex decoding is no frame
+	hexLooking, _ := hex.DecodeString("08001a08171c040d43f1ed81")
+	hexLookingAsHex, _ := hex.DecodeString("CAAaCBccBA1D8e2B")
+	tests := []struct {
+		name   string
+		text   string
+		format string
+		frame  []byte
+	}{
+		{"hex", hex.EncodeToString(frame), "auto", frame},
+		{"upper hex", strings.ToUpper(hex.EncodeToString(frame)), "auto", frame},
+		{"base64", base64.StdEncoding.EncodeToString(frame), "auto", frame},
+		{"raw url base64", base64.RawURLEncoding.EncodeToString(frame), "auto", frame},
+		{"spaces", "  " + base64.StdEncoding.EncodeToString(frame) + "\n", "auto", frame},
+		{"hex-looking base64", "CAAaCBccBA1D8e2B", "auto", hexLooking},
+		{"hex-looking base64 as hex", "CAAaCBccBA1D8e2B", "hex", hexLookingAsHex},
+		{"base64 as hex", base64.StdEncoding.EncodeToString(frame), "hex", nil},
+		{"neither", "not a frame!", "auto", nil},
+		{"unknown format", hex.EncodeToString(frame), "binary", nil},
+	}
+	for _, tt := range tests {
+		b, err := decodeFrame(tt.text, tt.format)
+		if tt.frame == nil {
+			if err == nil {
+				t.Errorf("%s: decoded to %x", tt.name, b)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(b, tt.frame) {
+			t.Errorf("%s: %x %v, expected %x", tt.name, b, err, tt.frame)
+		}
+	}
+}
+
+func TestInspectKeys(t *testing.T) {
+	connKey, flagKey := testInspectKey(1), testInspectKey(2)
+	connFile := filepath.Join(t.TempDir(), "conn.json")
+	connInfo := `{"Version":2,"AuthID":"1234","AuthKey":"` + base64.StdEncoding.EncodeToString(connKey) + `"}`
+	if err := ioutil.WriteFile(connFile, []byte(connInfo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// the hex of a key which is only made of the digits is valid base64 too, its length tells it apart
+	digitsKey := bytes.Repeat([]byte{0x12}, 256)
+	tests := []struct {
+		name     string
+		connFile string
+		key      string
+		authID   int64
+		expected []byte
+	}{
+		{"conn", connFile, "", 1234, connKey},
+		{"conn unknown auth id", connFile, "", 999, nil},
+		{"base64 key", "", base64.StdEncoding.EncodeToString(flagKey), 999, flagKey},
+		{"hex key", "", hex.EncodeToString(flagKey), 999, flagKey},
+		{"hex digits key", "", hex.EncodeToString(digitsKey), 999, digitsKey},
+		{"conn and key", connFile, hex.EncodeToString(flagKey), 1234, connKey},
+		{"key of the other auth ids", connFile, hex.EncodeToString(flagKey), 999, flagKey},
+		{"none", "", "", 1234, nil},
+	}
+	for _, tt := range tests {
+		keys, err := inspectKeys(tt.connFile, tt.key)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if k := keys(tt.authID); !bytes.Equal(k, tt.expected) {
+			t.Errorf("%s: the key of %d is %x", tt.name, tt.authID, k)
+		}
+	}
+
+	for _, key := range []string{hex.EncodeToString(flagKey[:255]), "not a key!"} {
+		if _, err := inspectKeys("", key); err == nil {
+			t.Errorf("the key %q is accepted", key)
+		}
+	}
+	if _, err := inspectKeys(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Error("a missing connection info is accepted")
+	}
+}
+
+// TestInspectFrame decrypts the frames by the keys of -conn and -key
+func TestInspectFrame(t *testing.T) {
+	connKey, flagKey := testInspectKey(1), testInspectKey(2)
+	connFile := filepath.Join(t.TempDir(), "conn.json")
+	connInfo := `{"Version":2,"AuthID":"1234","AuthKey":"` + base64.StdEncoding.EncodeToString(connKey) + `"}`
+	if err := ioutil.WriteFile(connFile, []byte(connInfo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		connFile string
+		key      string
+		frame    string
+		expected []string
+	}{
+		{"unencrypted", "", "", hex.EncodeToString(testFrame(t)), []string{`"Constructor": "SystemGetServerTime"`}},
+		{"conn", connFile, "", base64.StdEncoding.EncodeToString(sealFrame(t, 1234, connKey, "CONN")),
+			[]string{`"Items": "CONN"`, `"MessageTime": "2023-11-14T22:13:20Z"`, `"SessionID": "2"`}},
+		{"key", "", base64.StdEncoding.EncodeToString(flagKey), hex.EncodeToString(sealFrame(t, 999, flagKey, "KEY")),
+			[]string{`"Items": "KEY"`}},
+		{"no key", connFile, "", hex.EncodeToString(sealFrame(t, 999, flagKey, "KEY")),
+			[]string{`"Encrypted": true`}},
+	}
+	for _, tt := range tests {
+		keys, err := inspectKeys(tt.connFile, tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err = inspectFrame(&out, tt.frame, "auto", keys); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for _, e := range tt.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%s: %s is not printed in\n%s", tt.name, e, out.String())
+			}
+		}
+	}
+
+	// a frame which is decrypted by the wrong key fails
+	keys, _ := inspectKeys("", hex.EncodeToString(connKey))
+	if err := inspectFrame(&bytes.Buffer{}, hex.EncodeToString(sealFrame(t, 999, flagKey, "KEY")), "auto", keys); err == nil {
+		t.Error("a frame is decrypted by the wrong key")
+	}
+}
@@ -0,0 +1,234 @@
+// riverctl is a command-line client of River. It runs the handshake, sends the requests which are written
+// in the protobuf text format and prints the responses and the updates as JSON.
+//
+//	riverctl stub -listen 127.0.0.1:8080 -keys keys.json
+//	riverctl -root-key <key> -env staging -keys keys.json auth
+//	riverctl -root-key <key> -env staging -keys keys.json send SystemGetServerTime
+//	riverctl -root-key <key> -env staging -keys keys.json listen
+//	riverctl inspect -conn riverctl.conn.json < frames.txt
+//	riverctl replay -passphrase <passphrase> capture.json
+//	riverctl selftest -full
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"git.ronaksoft.com/river/web-wasm/cmd/internal/dump"
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/river"
+	"git.ronaksoft.com/river/web-wasm/stub"
+)
+
+// requestTimeout is how long the requests wait for their responses
+const requestTimeout = 30 * time.Second
+
+// handle is the account handle of riverctl, each connection info file belongs to a single account
+const handle = "riverctl"
+
+const usage = `Usage: riverctl [flags] <command>
+
+Commands:
+  auth                           run the handshake and save the auth key in the connection info file
+  send <Constructor> [body|-]    send the request, the body is in the protobuf text format or read from stdin
+  listen                         print the updates until interrupted
+  stub [-listen addr] [-keys f]  run the stub server, which writes its server keys to f
+  inspect [-conn f] [-key k] [frames]
+                                 decode the captured frames, base64 or hex, which are read from stdin if
+                                 they are not given, the auth keys of -conn or -key decrypt them
+  replay [-conn f] [-passphrase p] <capture>
+                                 replay the capture file through Decode and the dispatch of the browser,
+                                 the auth keys of -conn decrypt the frames of a redacted capture
+  selftest [-full] [-vectors]    check the crypto core against its known answers, or print them as JSON
+
+Flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("riverctl", flag.ExitOnError)
+	addr := fs.String("addr", "ws://127.0.0.1:8080/", "WebSocket address of the server")
+	keysFile := fs.String("keys", "keys.json", "server keys JSON, signed or plain")
+	connFile := fs.String("conn", "riverctl.conn.json", "connection info file")
+	rootKey := fs.String("root-key", "", "base64 root key which the server keys are signed with")
+	env := fs.String("env", river_conn.EnvProduction, "environment of the server keys")
+	clusterID := fs.Int("cluster", 0, "cluster id")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "stub":
+		err = runStub(args[1:])
+	case "inspect":
+		err = runInspect(args[1:])
+	case "replay":
+		err = runReplay(args[1:])
+	case "selftest":
+		err = runSelfTest(args[1:])
+	default:
+		river_conn.SetRootPublicKey(*rootKey, *env)
+		err = run(args, *addr, *keysFile, *connFile, int32(*clusterID), os.Stdin, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "riverctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the commands which connect to the server, the body of send - is read from stdin and the
+// responses and the updates are written to stdout
+func run(args []string, addr, keysFile, connFile string, clusterID int32, stdin io.Reader, stdout io.Writer) error {
+	serverKeys, err := ioutil.ReadFile(keysFile)
+	if err != nil {
+		return err
+	}
+	connInfo, err := ioutil.ReadFile(connFile)
+	if os.IsNotExist(err) {
+		connInfo = []byte("{}")
+	} else if err != nil {
+		return err
+	}
+
+	// only the connection info is kept, the handshakes are never resumed by riverctl
+	river_conn.SetStorage(func(data, key string) {
+		if key != river_conn.ConnInfoStorageKey(handle) {
+			return
+		}
+		if err := ioutil.WriteFile(connFile, []byte(data), 0600); err != nil {
+			fmt.Fprintln(os.Stderr, "riverctl:", err)
+		}
+	})
+
+	r := river.NewRiver(handle)
+	err = r.Load(string(connInfo), string(serverKeys))
+	if err != nil && err != _errors.ErrNoAuthKey {
+		return err
+	}
+	_, _, keyErr := r.ConnInfo.GetClusterKey(clusterID)
+	if args[0] != "auth" && keyErr != nil {
+		return errors.New("no auth key, run riverctl auth first")
+	}
+
+	c, err := dial(addr, r, clusterID, stdout)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	switch args[0] {
+	case "auth":
+		if err = c.auth(requestTimeout); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "auth key is saved in", connFile)
+	case "send":
+		return send(c, args[1:], stdin)
+	case "listen":
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		select {
+		case <-interrupt:
+		case <-c.Closed():
+			return errors.New("connection is closed")
+		}
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+	return nil
+}
+
+func send(c *client, args []string, stdin io.Reader) error {
+	if len(args) == 0 {
+		return errors.New("send needs the constructor")
+	}
+	constructor, ok := msg.ConstructorByName(args[0])
+	if !ok {
+		return fmt.Errorf("unknown constructor %s", args[0])
+	}
+	text := strings.Join(args[1:], " ")
+	if text == "-" {
+		b, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+
+	var body []byte
+	if m, ok := msg.NewMessage(constructor); ok {
+		if err := parseText(text, m); err != nil {
+			return err
+		}
+		var err error
+		if body, err = m.Marshal(); err != nil {
+			return err
+		}
+	} else if strings.TrimSpace(text) != "" {
+		return fmt.Errorf("%s has no body", args[0])
+	}
+
+	res, err := c.call(constructor, body, requestTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, dump.JSON(dump.Envelope(res)))
+	return nil
+}
+
+func runSelfTest(args []string) error {
+	fs := flag.NewFlagSet("riverctl selftest", flag.ExitOnError)
+	full := fs.Bool("full", false, "check the slow vectors too")
+	vectors := fs.Bool("vectors", false, "print the known answers, which are shared with the server")
+	_ = fs.Parse(args)
+
+	if *vectors {
+		fmt.Println(river.SelfTestVectorsJSON())
+		return nil
+	}
+	if err := river.SelfTest(*full); err != nil {
+		return err
+	}
+	fmt.Printf("self test of version %d passed\n", river.SelfTestVersion)
+	return nil
+}
+
+func runStub(args []string) error {
+	fs := flag.NewFlagSet("riverctl stub", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8080", "address to listen on")
+	keysFile := fs.String("keys", "keys.json", "file which the server keys are written to")
+	pkcs1 := fs.Bool("pkcs1", false, "pad the RSA key by PKCS#1 v1.5 rather than OAEP-SHA256, as the older server keys")
+	_ = fs.Parse(args)
+
+	padding := river_conn.PaddingOAEPSHA256
+	if *pkcs1 {
+		padding = river_conn.PaddingPKCS1v15
+	}
+	s, err := stub.NewServerWithPadding(padding)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(*keysFile, []byte(s.ServerKeys()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("stub server is listening on %s, connect by:\n", *listen)
+	fmt.Printf("  riverctl -addr ws://%s/ -keys %s -root-key %s -env %s auth\n", *listen, *keysFile, s.RootPublicKey(), stub.Env)
+	return http.ListenAndServe(*listen, s)
+}
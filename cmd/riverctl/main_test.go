@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/stub"
+)
+
+// testStub
+// The stub server of the tests, its server keys are written to keysFile
+type testStub struct {
+	*stub.Server
+	addr     string
+	keysFile string
+}
+
+func newTestStub(t *testing.T) *testStub {
+	s, err := stub.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	river_conn.SetRootPublicKey(s.RootPublicKey(), stub.Env)
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	if err = ioutil.WriteFile(keysFile, []byte(s.ServerKeys()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &testStub{Server: s, addr: "ws" + strings.TrimPrefix(srv.URL, "http") + "/", keysFile: keysFile}
+}
+
+// syncBuffer
+// The output of the client, which is written by its read loop too
+type syncBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
+
+// TestRunAuthAndSend runs riverctl auth, then send by the saved auth key with the body in the arguments
+// and read from stdin, then both on another cluster
+func TestRunAuthAndSend(t *testing.T) {
+	s := newTestStub(t)
+	// the requests other than SystemGetServerTime are echoed
+	s.HandleFunc(func(authID int64, req *msg.MessageEnvelope) *msg.MessageEnvelope {
+		if req.Constructor == msg.C_SystemGetServerTime {
+			data, _ := (&msg.SystemServerTime{Timestamp: 1700000000}).Marshal()
+			return &msg.MessageEnvelope{Constructor: msg.C_SystemServerTime, Message: data}
+		}
+		return req
+	})
+	connFile := filepath.Join(t.TempDir(), "riverctl.conn.json")
+	runCluster := func(clusterID int32, stdin string, args ...string) (string, error) {
+		out := &syncBuffer{}
+		err := run(args, s.addr, s.keysFile, connFile, clusterID, strings.NewReader(stdin), out)
+		return out.String(), err
+	}
+	runCmd := func(stdin string, args ...string) (string, error) {
+		return runCluster(0, stdin, args...)
+	}
+
+	if _, err := runCmd("", "send", "SystemGetServerTime"); err == nil || !strings.Contains(err.Error(), "no auth key") {
+		t.Fatalf("send before auth: %v", err)
+	}
+	out, err := runCmd("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "auth key is saved in "+connFile) {
+		t.Fatalf("auth printed %q", out)
+	}
+	connInfo, err := ioutil.ReadFile(connFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := river_conn.NewRiverConnection("test", string(connInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = conn.GetClusterKey(0); err != nil {
+		t.Fatalf("the auth key is not saved: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		expected []string
+	}{
+		{"no body", "", []string{"send", "SystemGetServerTime"}, []string{`"Constructor": "SystemServerTime"`, `"Timestamp": 1700000000`}},
+		{"body", "", []string{"send", "Error", `Code: "E01"`, `Items: "ARGS"`}, []string{`"Code": "E01"`, `"Items": "ARGS"`}},
+		{"stdin", "Code: \"E02\"\nItems: \"STDIN\"\n", []string{"send", "Error", "-"}, []string{`"Code": "E02"`, `"Items": "STDIN"`}},
+	}
+	for _, tt := range tests {
+		out, err := runCmd(tt.stdin, tt.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, e := range tt.expected {
+			if !strings.Contains(out, e) {
+				t.Errorf("%s: %s is not printed in\n%s", tt.name, e, out)
+			}
+		}
+	}
+
+	if _, err = runCmd("", "send", "NoSuchConstructor"); err == nil {
+		t.Fatal("an unknown constructor is sent")
+	}
+
+	// -cluster authenticates a cluster which has no key yet, the key of the default cluster is kept
+	if _, err = runCluster(2, "", "auth"); err != nil {
+		t.Fatalf("-cluster 2 auth: %v", err)
+	}
+	if out, err = runCluster(2, "", "send", "Error", `Items: "CLUSTER"`); err != nil || !strings.Contains(out, `"Items": "CLUSTER"`) {
+		t.Fatalf("-cluster 2 send: %v\n%s", err, out)
+	}
+	if connInfo, err = ioutil.ReadFile(connFile); err != nil {
+		t.Fatal(err)
+	}
+	if conn, err = river_conn.NewRiverConnection("test", string(connInfo)); err != nil {
+		t.Fatal(err)
+	}
+	defaultID, _, err := conn.GetClusterKey(0)
+	if err != nil {
+		t.Fatalf("the key of the default cluster is dropped: %v", err)
+	}
+	if authID, _, err := conn.GetClusterKey(2); err != nil || authID == defaultID {
+		t.Fatalf("the key of cluster 2 is %d %v", authID, err)
+	}
+	if _, err = runCluster(3, "", "send", "SystemGetServerTime"); err == nil || !strings.Contains(err.Error(), "no auth key") {
+		t.Fatalf("-cluster 3 send before auth: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"git.ronaksoft.com/river/web-wasm/cmd/internal/dump"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/river"
+)
+
+// runReplay replays the capture file which is written by wasmStopCapture, the dispatched envelopes are
+// printed in their order
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("riverctl replay", flag.ExitOnError)
+	connFile := fs.String("conn", "", "connection info file whose auth keys decrypt the frames of a redacted capture")
+	passphrase := fs.String("passphrase", "", "passphrase of an encrypted capture, or RIVER_CAPTURE_PASSPHRASE")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("replay needs the capture file")
+	}
+	if *passphrase == "" {
+		*passphrase = os.Getenv("RIVER_CAPTURE_PASSPHRASE")
+	}
+
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	f, err := river.OpenCapture(data, []byte(*passphrase))
+	if err != nil {
+		return err
+	}
+	var connInfo []byte
+	if *connFile != "" {
+		if connInfo, err = ioutil.ReadFile(*connFile); err != nil {
+			return err
+		}
+	}
+
+	res, err := river.Replay(f, string(connInfo), printDispatcher{out: os.Stdout})
+	if err != nil {
+		return err
+	}
+	for _, e := range res.Errors {
+		fmt.Fprintf(os.Stderr, "riverctl: frame %d: %v\n", e.Frame, e.Err)
+	}
+	for _, idx := range res.TimeSyncs {
+		fmt.Fprintf(os.Stderr, "riverctl: frame %d: time sync is needed\n", idx)
+	}
+	fmt.Fprintf(os.Stderr, "replayed %d inbound and %d outbound frames, %d dropped before the capture\n",
+		res.Inbound, res.Outbound, f.Dropped)
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("%d of %d inbound frames failed", len(res.Errors), res.Inbound)
+	}
+	return nil
+}
+
+// printDispatcher prints the dispatched envelopes as JSON
+type printDispatcher struct {
+	out io.Writer
+}
+
+func (d printDispatcher) OnUpdate(data []byte) {
+	d.print(&msg.MessageEnvelope{Constructor: msg.C_UpdateContainer, Message: data})
+}
+
+func (d printDispatcher) OnMessage(requestID uint64, constructor int64, data []byte) {
+	d.print(&msg.MessageEnvelope{
+		Constructor: constructor,
+		RequestID:   requestID,
+		Message:     data,
+	})
+}
+
+func (d printDispatcher) print(env *msg.MessageEnvelope) {
+	fmt.Fprintln(d.out, dump.JSON(dump.Envelope(env)))
+}
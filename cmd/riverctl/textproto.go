@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// enums are the values of the enums by their names in the protobuf tags
+var enums = map[string]map[string]int32{
+	"msg.UserStatus":                 msg.UserStatus_value,
+	"msg.GroupFlags":                 msg.GroupFlags_value,
+	"msg.InitAuthCompleted_Statuses": msg.InitAuthCompleted_Statuses_value,
+}
+
+// parseText fills m, a pointer to a generated message, by the protobuf text format, e.g.
+// `Code: "E01" TemplateItems: ["a", "b"] Header { Key: "k" Value: "v" }`
+func parseText(text string, m interface{}) error {
+	p := &textParser{s: text}
+	if err := p.readStruct(reflect.ValueOf(m).Elem(), ""); err != nil {
+		return fmt.Errorf("line %d: %v", p.line+1, err)
+	}
+	return nil
+}
+
+// textParser
+type textParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+// next returns the next token, the empty token is the end of the text
+func (p *textParser) next() (string, error) {
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return p.token()
+		}
+	}
+	return "", nil
+}
+
+func (p *textParser) token() (string, error) {
+	start := p.pos
+	switch c := p.s[p.pos]; {
+	case strings.IndexByte(":{}<>[],;", c) >= 0:
+		p.pos++
+	case c == '"' || c == '\'':
+		p.pos++
+		for p.pos < len(p.s) && p.s[p.pos] != c {
+			if p.s[p.pos] == '\\' {
+				p.pos++
+			}
+			if p.pos < len(p.s) && p.s[p.pos] == '\n' {
+				return "", errors.New("unterminated string")
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.s) {
+			return "", errors.New("unterminated string")
+		}
+		p.pos++
+	default:
+		for p.pos < len(p.s) && !isDelimiter(p.s[p.pos]) {
+			p.pos++
+		}
+	}
+	return p.s[start:p.pos], nil
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n#:{}<>[],;\"'", c) >= 0
+}
+
+// peek returns the next token without consuming it
+func (p *textParser) peek() (string, error) {
+	pos, line := p.pos, p.line
+	tok, err := p.next()
+	p.pos, p.line = pos, line
+	return tok, err
+}
+
+// readStruct reads the fields until the terminator, which is empty at the top level
+func (p *textParser) readStruct(v reflect.Value, terminator string) error {
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case terminator:
+			return nil
+		case "":
+			return fmt.Errorf("expected %q", terminator)
+		}
+
+		f, enum, ok := fieldByName(v, tok)
+		if !ok {
+			return fmt.Errorf("unknown field %s of %s", tok, v.Type().Name())
+		}
+		if err = p.readField(f, enum); err != nil {
+			return fmt.Errorf("%s: %v", tok, err)
+		}
+
+		// the fields could be separated by a comma or a semicolon
+		if tok, err = p.peek(); err != nil {
+			return err
+		}
+		if tok == "," || tok == ";" {
+			_, _ = p.next()
+		}
+	}
+}
+
+// fieldByName finds the field by its name in the protobuf tag, it returns the name of its enum if it has one
+func fieldByName(v reflect.Value, name string) (f reflect.Value, enum string, ok bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("protobuf")
+		if tag == "" {
+			continue
+		}
+		found := false
+		for _, part := range strings.Split(tag, ",") {
+			switch {
+			case part == "name="+name:
+				found = true
+			case strings.HasPrefix(part, "enum="):
+				enum = strings.TrimPrefix(part, "enum=")
+			}
+		}
+		if found {
+			return v.Field(i), enum, true
+		}
+		enum = ""
+	}
+	return reflect.Value{}, "", false
+}
+
+func (p *textParser) readField(f reflect.Value, enum string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	isMessage := isMessageType(f.Type())
+	if tok == ":" {
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	} else if !isMessage {
+		return errors.New("expected ':'")
+	}
+
+	repeated := f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8
+	if tok == "[" && repeated {
+		for {
+			if tok, err = p.next(); err != nil {
+				return err
+			}
+			if tok == "]" {
+				return nil
+			}
+			if err = p.readValue(f, tok, enum); err != nil {
+				return err
+			}
+			if tok, err = p.next(); err != nil {
+				return err
+			}
+			if tok == "]" {
+				return nil
+			}
+			if tok != "," {
+				return errors.New("expected ',' or ']'")
+			}
+		}
+	}
+	return p.readValue(f, tok, enum)
+}
+
+// readValue sets the value of the field, or appends it to the repeated field
+func (p *textParser) readValue(f reflect.Value, tok string, enum string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+		elem := reflect.New(f.Type().Elem()).Elem()
+		if err := p.readValue(elem, tok, enum); err != nil {
+			return err
+		}
+		f.Set(reflect.Append(f, elem))
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct {
+		var terminator string
+		switch tok {
+		case "{":
+			terminator = "}"
+		case "<":
+			terminator = ">"
+		default:
+			return errors.New("expected '{'")
+		}
+		m := reflect.New(f.Type().Elem())
+		if err := p.readStruct(m.Elem(), terminator); err != nil {
+			return err
+		}
+		f.Set(m)
+		return nil
+	}
+	return setScalar(f, tok, enum)
+}
+
+func setScalar(f reflect.Value, tok string, enum string) error {
+	switch f.Kind() {
+	case reflect.String:
+		s, err := unquote(tok)
+		if err != nil {
+			return err
+		}
+		f.SetString(string(s))
+	case reflect.Slice:
+		b, err := unquote(tok)
+		if err != nil {
+			return err
+		}
+		f.SetBytes(b)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(tok)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		if values, ok := enums[enum]; ok {
+			if x, ok := values[tok]; ok {
+				f.SetInt(int64(x))
+				return nil
+			}
+		}
+		x, err := strconv.ParseInt(tok, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(x)
+	case reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(tok, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(tok, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(x)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return nil
+}
+
+func isMessageType(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+}
+
+// unquote decodes the quoted string of the text format, the escapes could be any bytes
+func unquote(tok string) ([]byte, error) {
+	if len(tok) < 2 || (tok[0] != '"' && tok[0] != '\'') || tok[len(tok)-1] != tok[0] {
+		return nil, fmt.Errorf("expected a quoted string, found %s", tok)
+	}
+	s := tok[1 : len(tok)-1]
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return nil, errors.New("invalid escape")
+		}
+		switch c := s[i]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'a':
+			out = append(out, '\a')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'v':
+			out = append(out, '\v')
+		case '\\', '\'', '"', '?':
+			out = append(out, c)
+		case 'x', 'X':
+			n := 0
+			for n < 2 && i+1+n < len(s) && isHex(s[i+1+n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, errors.New("invalid escape")
+			}
+			x, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+			out = append(out, byte(x))
+			i += n
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := 1
+			for n < 3 && i+n < len(s) && s[i+n] >= '0' && s[i+n] <= '7' {
+				n++
+			}
+			x, err := strconv.ParseUint(s[i:i+n], 8, 8)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, byte(x))
+			i += n - 1
+		default:
+			return nil, fmt.Errorf("invalid escape \\%c", c)
+		}
+	}
+	return out, nil
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,373 @@
+// wasmsize reports the size of a wasm binary per Go package and fails if the binary is over its budget.
+// The code of each function is counted to its package by the name section, which go build and tinygo
+// build write unless they are told to strip it, so build without -s and let -strip remove it afterwards.
+// go build replaces the slashes of the names, -pkg maps them back to the packages of the build.
+//
+//	wasmsize -budget 6291456 -strip -pkg . river.wasm
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Sections of the wasm binary which are reported
+const (
+	sectionCustom = 0
+	sectionImport = 2
+	sectionCode   = 10
+	sectionData   = 11
+)
+
+const usage = `Usage: wasmsize [flags] <file.wasm>
+
+Prints the code bytes of each package, the data and the other sections, and exits with 1 if the binary
+is larger than -budget.
+
+Flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("wasmsize", flag.ExitOnError)
+	budget := fs.Int("budget", 0, "maximum size of the binary in bytes, zero does not check it")
+	strip := fs.Bool("strip", false, "remove the name and the debug sections from the binary after the report")
+	pkg := fs.String("pkg", "", "Go package which the binary is built from by go build, e.g. .")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(fs.Arg(0), *pkg, *budget, *strip); err != nil {
+		fmt.Fprintln(os.Stderr, "wasmsize:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, pkg string, budget int, strip bool) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	m, err := parseModule(data)
+	if err != nil {
+		return err
+	}
+	if pkg != "" {
+		if m.packages, err = goPackages(pkg); err != nil {
+			return err
+		}
+	}
+	if len(m.names) == 0 {
+		fmt.Fprintln(os.Stderr, "wasmsize: the binary has no name section, the code is not reported per package")
+	}
+	if strip {
+		data = m.stripped(data)
+		if err = ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	m.report(os.Stdout, len(data))
+	if budget > 0 && len(data) > budget {
+		return fmt.Errorf("%s is %d bytes, %d bytes over the budget of %d bytes", path, len(data), len(data)-budget, budget)
+	}
+	return nil
+}
+
+// section of the binary, start and end include its id and size
+type section struct {
+	id         byte
+	name       string
+	start, end int
+}
+
+// module
+// Sizes of the code of the functions by their index, which counts the imported functions first
+type module struct {
+	sections []section
+	imported int
+	code     map[int]int
+	names    map[int]string
+	packages map[string]string
+}
+
+func parseModule(data []byte) (*module, error) {
+	if len(data) < 8 || string(data[:4]) != "\x00asm" {
+		return nil, errors.New("not a wasm binary")
+	}
+	m := &module{
+		code:  make(map[int]int),
+		names: make(map[int]string),
+	}
+	r := &reader{data: data, pos: 8}
+	for r.pos < len(data) {
+		s := section{start: r.pos}
+		s.id = r.byte()
+		size := r.uint()
+		if r.err != nil || size > len(data)-r.pos {
+			return nil, errors.New("truncated section")
+		}
+		s.end = r.pos + size
+		body := &reader{data: data[:s.end], pos: r.pos}
+		switch s.id {
+		case sectionCustom:
+			s.name = body.name()
+			if s.name == "name" {
+				m.readNames(body)
+			}
+		case sectionImport:
+			m.readImports(body)
+		case sectionCode:
+			m.readCode(body)
+		}
+		if body.err != nil {
+			return nil, fmt.Errorf("section %d: %v", s.id, body.err)
+		}
+		m.sections = append(m.sections, s)
+		r.pos = s.end
+	}
+	return m, nil
+}
+
+func (m *module) readImports(r *reader) {
+	for n := r.uint(); n > 0 && r.err == nil; n-- {
+		r.name()
+		r.name()
+		switch r.byte() {
+		case 0: // function
+			r.uint()
+			m.imported++
+		case 1: // table
+			r.byte()
+			r.limits()
+		case 2: // memory
+			r.limits()
+		case 3: // global
+			r.byte()
+			r.byte()
+		default:
+			r.err = errors.New("unknown import")
+		}
+	}
+}
+
+func (m *module) readCode(r *reader) {
+	n := r.uint()
+	for i := 0; i < n && r.err == nil; i++ {
+		start := r.pos
+		size := r.uint()
+		r.skip(size)
+		m.code[m.imported+i] = r.pos - start
+	}
+}
+
+// readNames reads the function names, the other subsections are skipped
+func (m *module) readNames(r *reader) {
+	for r.pos < len(r.data) && r.err == nil {
+		id := r.byte()
+		size := r.uint()
+		if id != 1 {
+			r.skip(size)
+			continue
+		}
+		for n := r.uint(); n > 0 && r.err == nil; n-- {
+			idx := r.uint()
+			m.names[idx] = r.name()
+		}
+	}
+}
+
+// stripped returns the binary without the name and the debug sections
+func (m *module) stripped(data []byte) []byte {
+	out := append([]byte(nil), data[:8]...)
+	for _, s := range m.sections {
+		if s.id == sectionCustom && (s.name == "name" || strings.HasPrefix(s.name, ".debug_")) {
+			continue
+		}
+		out = append(out, data[s.start:s.end]...)
+	}
+	return out
+}
+
+func (m *module) report(w *os.File, total int) {
+	packages := make(map[string]int)
+	code := 0
+	for idx, size := range m.code {
+		packages[m.packageOf(m.names[idx])] += size
+		code += size
+	}
+	data, other := 0, total-code
+	for _, s := range m.sections {
+		if s.id == sectionData {
+			data += s.end - s.start
+		}
+	}
+	other -= data
+
+	names := make([]string, 0, len(packages))
+	for p := range packages {
+		names = append(names, p)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if packages[names[i]] != packages[names[j]] {
+			return packages[names[i]] > packages[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "bytes\t%\tpackage\t")
+	for _, p := range names {
+		fmt.Fprintf(tw, "%d\t%.1f\t%s\t\n", packages[p], percent(packages[p], total), p)
+	}
+	fmt.Fprintf(tw, "%d\t%.1f\t(code)\t\n", code, percent(code, total))
+	fmt.Fprintf(tw, "%d\t%.1f\t(data)\t\n", data, percent(data, total))
+	fmt.Fprintf(tw, "%d\t%.1f\t(other sections)\t\n", other, percent(other, total))
+	fmt.Fprintf(tw, "%d\t100.0\t(total)\t\n", total)
+	_ = tw.Flush()
+}
+
+// goPackages returns the import paths of the dependencies of the js/wasm build of pkg by their names in
+// the name section
+func goPackages(pkg string) (map[string]string, error) {
+	cmd := exec.Command("go", "list", "-deps", pkg)
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]string)
+	for _, p := range strings.Fields(string(out)) {
+		packages[goName(p)] = p
+	}
+	return packages, nil
+}
+
+// goName returns the name as it is written by go build, which replaces all but [0-9A-Za-z_.] by _
+func goName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && c != '_' && c != '.' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// packageOf returns the package of the function name, e.g. git.ronaksoft.com/river/web-wasm/river for
+// git.ronaksoft.com/river/web-wasm/river.(*River).Decode, or for its name by go build if the packages
+// of the build are known
+func (m *module) packageOf(name string) string {
+	if name == "" {
+		return "(unnamed)"
+	}
+	// the longest package which name starts with, the domains of the packages have dots too
+	best := ""
+	for i := strings.IndexByte(name, '.'); i > 0; {
+		if p, ok := m.packages[name[:i]]; ok {
+			best = p
+		}
+		j := strings.IndexByte(name[i+1:], '.')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	if best != "" {
+		return best
+	}
+	// type parameters could have slashes and the methods of tinygo could start by (*
+	if i := strings.IndexByte(name, '['); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimLeft(name, "(*")
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot <= 0 {
+		// the C functions which tinygo links, e.g. memcpy
+		return "(c)"
+	}
+	return name[:slash+1+dot]
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// reader of the LEB128 numbers and the names of the binary, the first error is kept
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || r.pos >= len(r.data) {
+		r.fail()
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) uint() int {
+	n, shift := 0, uint(0)
+	for r.err == nil {
+		b := r.byte()
+		n |= int(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+		if shift += 7; shift > 28 {
+			r.fail()
+		}
+	}
+	return n
+}
+
+func (r *reader) name() string {
+	size := r.uint()
+	start := r.pos
+	r.skip(size)
+	if r.err != nil {
+		return ""
+	}
+	return string(r.data[start:r.pos])
+}
+
+func (r *reader) limits() {
+	if r.byte()&1 != 0 {
+		r.uint()
+	}
+	r.uint()
+}
+
+func (r *reader) skip(n int) {
+	if r.err != nil || n > len(r.data)-r.pos {
+		r.fail()
+		return
+	}
+	r.pos += n
+}
+
+func (r *reader) fail() {
+	if r.err == nil {
+		r.err = errors.New("truncated")
+	}
+}
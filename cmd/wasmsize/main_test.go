@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// root is the package of the wasm binary, the budgets are the defaults of go-build.sh and tiny-build.sh
+const (
+	root         = "../.."
+	goBudget     = 6291456
+	tinyGoBudget = 3145728
+)
+
+// forbidden are the packages which the wasm binary must not link, the JSON is read and written by jsonx
+var forbidden = []string{"github.com/mailru/easyjson", "encoding/json", "net/http"}
+
+func budget(t *testing.T, env string, def int) int {
+	v := os.Getenv(env)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		t.Fatalf("%s: %v", env, err)
+	}
+	return n
+}
+
+func build(t *testing.T, name string, args ...string) string {
+	out := filepath.Join(t.TempDir(), "river.wasm")
+	cmd := exec.Command(name, append(append(args, "-o", out), root)...)
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s build: %v\n%s", name, err, b)
+	}
+	return out
+}
+
+func TestGoBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the wasm binary")
+	}
+	out := build(t, "go", "build", "-ldflags=-w")
+	if err := run(out, root, budget(t, "RIVER_MAX_WASM_SIZE", goBudget), true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTinyGoBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the wasm binary")
+	}
+	if _, err := exec.LookPath("tinygo"); err != nil {
+		t.Skip("tinygo is not installed")
+	}
+	out := build(t, "tinygo", "build", "-target", "wasm")
+	if err := run(out, "", budget(t, "RIVER_MAX_TINY_WASM_SIZE", tinyGoBudget), true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	cmd := exec.Command("go", "list", "-deps", root)
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	b, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range strings.Fields(string(b)) {
+		for _, f := range forbidden {
+			if p == f || strings.HasPrefix(p, f+"/") {
+				t.Errorf("the wasm binary depends on %s", p)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package river_conn
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// clockSampleSize is the number of the latest samples which the time difference is estimated by
+	clockSampleSize = 8
+	// MaxClockSkew is the difference in milliseconds between the server time of a message and our
+	// estimation which triggers a new sync
+	MaxClockSkew = 30 * 1000
+	// clockResyncInterval is the minimum time in milliseconds between two syncs triggered by the skew
+	clockResyncInterval = 60 * 1000
+	// clockSaveThreshold is the change of DiffTime in milliseconds which is worth saving
+	clockSaveThreshold = 1000
+)
+
+// clockSample
+// offset is the server time minus the local time at the moment the server stamped the reply
+type clockSample struct {
+	offset int64
+	rtt    int64
+}
+
+// clock
+// Samples of the server time which DiffTime is estimated from
+type clock struct {
+	mtx      sync.Mutex
+	samples  []clockSample
+	resyncAt int64
+	// callerOffset is the offset of the server time which is set by the caller, its round trip is
+	// unknown, so it is used only until a sample of ours arrives
+	callerOffset int64
+	// local replaces LocalTime, e.g. by the time of the captured frames while they are replayed
+	local func() int64
+}
+
+// estimate returns the median offset of the faster half of the samples, the smaller the round trip
+// is the less the reply could be delayed in either direction, and the median drops the outliers
+func (c *clock) estimate() int64 {
+	if len(c.samples) == 0 {
+		return c.callerOffset
+	}
+	samples := make([]clockSample, len(c.samples))
+	copy(samples, c.samples)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].rtt < samples[j].rtt
+	})
+	samples = samples[:(len(samples)+1)/2]
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].offset < samples[j].offset
+	})
+	return samples[len(samples)/2].offset
+}
+
+// LocalTime returns the local unix time in milliseconds
+func LocalTime() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// SetLocalClock replaces the local clock of the connection, nil restores LocalTime
+func (v *RiverConnection) SetLocalClock(local func() int64) {
+	v.clock.mtx.Lock()
+	v.clock.local = local
+	v.clock.mtx.Unlock()
+}
+
+// LocalTime returns the local unix time in milliseconds by the clock of the connection
+func (v *RiverConnection) LocalTime() int64 {
+	v.clock.mtx.Lock()
+	local := v.clock.local
+	v.clock.mtx.Unlock()
+	if local != nil {
+		return local()
+	}
+	return LocalTime()
+}
+
+// AddTimeSample adds the server time in seconds which is received in the reply of a request sent at
+// sentAt and received at receivedAt, both in local milliseconds, then DiffTime is estimated again.
+// save is true if DiffTime changed enough to be persisted, the caller saves the connection info under
+// its own lock, so the messages of the next load are stamped right before any sync.
+func (v *RiverConnection) AddTimeSample(serverTime, sentAt, receivedAt int64) (save bool) {
+	if serverTime <= 0 || receivedAt < sentAt {
+		return false
+	}
+	rtt := receivedAt - sentAt
+	// the server time is truncated to seconds, so the middle of that second is taken, and the
+	// reply is assumed to be stamped in the middle of the round trip
+	sample := clockSample{
+		offset: serverTime*1000 + 500 - (sentAt + rtt/2),
+		rtt:    rtt,
+	}
+	return v.updateDiffTime(func(c *clock) {
+		c.samples = append(c.samples, sample)
+		if len(c.samples) > clockSampleSize {
+			c.samples = c.samples[len(c.samples)-clockSampleSize:]
+		}
+	})
+}
+
+// updateDiffTime estimates DiffTime again after the samples are updated
+func (v *RiverConnection) updateDiffTime(update func(c *clock)) bool {
+	v.clock.mtx.Lock()
+	update(v.clock)
+	v.clock.resyncAt = 0
+	diffTime := v.clock.estimate()
+	oldDiffTime := atomic.SwapInt64(&v.DiffTime, diffTime)
+	v.clock.mtx.Unlock()
+
+	return diffTime-oldDiffTime >= clockSaveThreshold || oldDiffTime-diffTime >= clockSaveThreshold
+}
+
+// CheckClockSkew returns true if the server time in seconds of a received message is too far from
+// our estimation and the time should be synced again
+func (v *RiverConnection) CheckClockSkew(serverTime int64) bool {
+	now := v.LocalTime()
+	skew := serverTime*1000 - (now + atomic.LoadInt64(&v.DiffTime))
+	// the server time is truncated to seconds
+	if skew > -MaxClockSkew-1000 && skew < MaxClockSkew {
+		return false
+	}
+
+	v.clock.mtx.Lock()
+	defer v.clock.mtx.Unlock()
+	if v.clock.resyncAt != 0 && now-v.clock.resyncAt < clockResyncInterval {
+		return false
+	}
+	v.clock.resyncAt = now
+	return true
+}
+
+// SetServerTime sets the server time in seconds which is measured by the caller, the samples of ours
+// are preferred to it. save is as of AddTimeSample.
+func (v *RiverConnection) SetServerTime(timestamp int64) (save bool) {
+	if timestamp <= 0 {
+		return false
+	}
+	offset := timestamp*1000 + 500 - v.LocalTime()
+	return v.updateDiffTime(func(c *clock) {
+		c.callerOffset = offset
+	})
+}
+
+// Now returns the server time in seconds
+func (v *RiverConnection) Now() int64 {
+	return v.NowMs() / 1000
+}
+
+// NowMs returns the server time in milliseconds
+func (v *RiverConnection) NowMs() int64 {
+	return v.LocalTime() + atomic.LoadInt64(&v.DiffTime)
+}
@@ -0,0 +1,80 @@
+package river_conn
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// testClock returns a connection info which local clock is fixed at now milliseconds
+func testClock(now int64) *RiverConnection {
+	v := &RiverConnection{clock: new(clock)}
+	v.SetLocalClock(func() int64 { return now })
+	return v
+}
+
+func TestAddTimeSample(t *testing.T) {
+	v := testClock(1000000)
+	// the reply of a request sent at 999000 is received at 999200, the server stamped 2000 seconds
+	if !v.AddTimeSample(2000, 999000, 999200) {
+		t.Fatal("the first sample is not saved")
+	}
+	if d := atomic.LoadInt64(&v.DiffTime); d != 2000*1000+500-999100 {
+		t.Fatalf("DiffTime %d", d)
+	}
+	// a sample of the same offset does not change DiffTime enough to be saved
+	if v.AddTimeSample(2001, 1000000, 1000200) {
+		t.Fatal("an unchanged DiffTime is saved")
+	}
+	if v.AddTimeSample(0, 1000000, 1000200) || v.AddTimeSample(2001, 1000200, 1000000) {
+		t.Fatal("an invalid sample is added")
+	}
+}
+
+func TestSetServerTimeIsNotPreferred(t *testing.T) {
+	v := testClock(1000000)
+	v.SetServerTime(5000)
+	if d := atomic.LoadInt64(&v.DiffTime); d != 5000*1000+500-1000000 {
+		t.Fatalf("DiffTime %d of the server time which is set", d)
+	}
+	// a measured sample is preferred to the one which is set, although its round trip is not zero
+	v.AddTimeSample(2000, 999000, 999200)
+	if d := atomic.LoadInt64(&v.DiffTime); d != 2000*1000+500-999100 {
+		t.Fatalf("DiffTime %d, expected the measured sample", d)
+	}
+	v.SetServerTime(5000)
+	if d := atomic.LoadInt64(&v.DiffTime); d != 2000*1000+500-999100 {
+		t.Fatalf("DiffTime %d, expected the measured sample", d)
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	now := int64(1000000)
+	v := &RiverConnection{clock: new(clock)}
+	v.SetLocalClock(func() int64 { return now })
+	v.AddTimeSample(2000, now, now)
+	// the server time of the messages is in seconds, our estimation of it is 2000.5
+	tests := []struct {
+		name       string
+		advance    int64
+		serverTime int64
+		resync     bool
+	}{
+		{"in time", 0, 2000, false},
+		{"truncated", 0, 2000 - MaxClockSkew/1000, false},
+		{"ahead", 0, 2000 + MaxClockSkew/1000 + 1, true},
+		{"ahead again", 1000, 2000 + MaxClockSkew/1000 + 2, false},
+		{"behind before the interval", clockResyncInterval - 2000, 2000, false},
+		{"behind after the interval", 1000, 2000 - MaxClockSkew/1000 - 10, true},
+	}
+	for _, tt := range tests {
+		now += tt.advance
+		if resync := v.CheckClockSkew(tt.serverTime); resync != tt.resync {
+			t.Errorf("%s: resync %v at %d", tt.name, resync, now)
+		}
+	}
+	// a new sample allows the next sync at once
+	v.AddTimeSample(now/1000+5000, now, now)
+	if !v.CheckClockSkew(now / 1000) {
+		t.Error("the skew after a new sample does not resync")
+	}
+}
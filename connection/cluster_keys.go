@@ -0,0 +1,117 @@
+package river_conn
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"strconv"
+)
+
+// DefaultClusterID
+// The key of the default cluster is kept in AuthID and AuthKey of RiverConnection
+const DefaultClusterID int32 = 0
+
+// GetClusterKey returns the auth key which is negotiated with the cluster
+func (v *RiverConnection) GetClusterKey(clusterID int32) (authID int64, authKey []byte, err error) {
+	if clusterID == DefaultClusterID {
+		if v.AuthID == 0 {
+			return 0, nil, _errors.ErrNoAuthKey
+		}
+		return v.AuthID, v.AuthKey[:], nil
+	}
+	for idx := range v.Clusters {
+		if v.Clusters[idx].ClusterID == clusterID {
+			return v.Clusters[idx].AuthID, v.Clusters[idx].AuthKey[:], nil
+		}
+	}
+	return 0, nil, _errors.ErrNoAuthKey
+}
+
+// GetKeyByAuthID looks up the auth key among all the clusters by its auth id
+func (v *RiverConnection) GetKeyByAuthID(authID int64) (authKey []byte, err error) {
+	if authID == 0 {
+		return nil, _errors.ErrNoAuthKey
+	}
+	if v.AuthID == authID {
+		return v.AuthKey[:], nil
+	}
+	for idx := range v.Clusters {
+		if v.Clusters[idx].AuthID == authID {
+			return v.Clusters[idx].AuthKey[:], nil
+		}
+	}
+	return nil, _errors.ErrNoAuthKey
+}
+
+// SetClusterKey sets (or replaces) the auth key of the cluster
+func (v *RiverConnection) SetClusterKey(clusterID int32, authID int64, authKey [256]byte) {
+	if clusterID == DefaultClusterID {
+		v.AuthID = authID
+		v.AuthKey = authKey
+		return
+	}
+	for idx := range v.Clusters {
+		if v.Clusters[idx].ClusterID == clusterID {
+			v.Clusters[idx].AuthID = authID
+			v.Clusters[idx].AuthKey = authKey
+			return
+		}
+	}
+	v.Clusters = append(v.Clusters, ClusterKey{
+		ClusterID: clusterID,
+		AuthID:    authID,
+		AuthKey:   authKey,
+	})
+}
+
+// RemoveClusterKey drops the auth key of the cluster
+func (v *RiverConnection) RemoveClusterKey(clusterID int32) {
+	if clusterID == DefaultClusterID {
+		v.AuthID = 0
+		v.AuthKey = [256]byte{}
+		return
+	}
+	for idx := range v.Clusters {
+		if v.Clusters[idx].ClusterID == clusterID {
+			v.Clusters = append(v.Clusters[:idx], v.Clusters[idx+1:]...)
+			return
+		}
+	}
+}
+
+// ExportClusterKey serializes the auth key of the cluster, so it could be imported for
+// another cluster which shares the auth keys with this one
+func (v *RiverConnection) ExportClusterKey(clusterID int32) ([]byte, error) {
+	authID, authKey, err := v.GetClusterKey(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	ck := ClusterKeyJS{
+		ClusterID: clusterID,
+		AuthID:    strconv.FormatInt(authID, 10),
+	}
+	copy(ck.AuthKey[:], authKey)
+	return ck.MarshalJSON()
+}
+
+// ImportClusterKey binds the key which is exported by ExportClusterKey to the cluster
+func (v *RiverConnection) ImportClusterKey(clusterID int32, data []byte) error {
+	ck := ClusterKeyJS{}
+	if err := ck.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	authID, err := strconv.ParseInt(ck.AuthID, 10, 64)
+	if err != nil {
+		return err
+	}
+	if authID == 0 {
+		return _errors.ErrNoAuthKey
+	}
+	// the auth id is the last 8 bytes of the key hash, a key which is imported by another id is tampered
+	h := sha256.Sum256(ck.AuthKey[:])
+	if int64(binary.LittleEndian.Uint64(h[24:32])) != authID {
+		return _errors.ErrAuthIDMismatch
+	}
+	v.SetClusterKey(clusterID, authID, [256]byte(ck.AuthKey))
+	return nil
+}
@@ -0,0 +1,115 @@
+package river_conn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// testClusterKey returns an auth key and its auth id
+func testClusterKey(seed byte) (int64, [256]byte) {
+	var k [256]byte
+	for i := range k {
+		k[i] = seed + byte(i)
+	}
+	h := sha256.Sum256(k[:])
+	return int64(binary.LittleEndian.Uint64(h[24:32])), k
+}
+
+func TestClusterKeys(t *testing.T) {
+	v := &RiverConnection{clock: new(clock)}
+	if _, _, err := v.GetClusterKey(DefaultClusterID); err != _errors.ErrNoAuthKey {
+		t.Fatalf("the default cluster has a key: %v", err)
+	}
+	defaultID, defaultKey := testClusterKey(1)
+	otherID, otherKey := testClusterKey(2)
+	v.SetClusterKey(DefaultClusterID, defaultID, defaultKey)
+	v.SetClusterKey(2, otherID, otherKey)
+	if v.AuthID != defaultID || len(v.Clusters) != 1 {
+		t.Fatalf("the default key is kept in AuthID %d and %d clusters", v.AuthID, len(v.Clusters))
+	}
+	for _, tt := range []struct {
+		clusterID int32
+		authID    int64
+		authKey   [256]byte
+	}{{DefaultClusterID, defaultID, defaultKey}, {2, otherID, otherKey}} {
+		authID, authKey, err := v.GetClusterKey(tt.clusterID)
+		if err != nil || authID != tt.authID || !bytes.Equal(authKey, tt.authKey[:]) {
+			t.Fatalf("the key of cluster %d is %d %v", tt.clusterID, authID, err)
+		}
+		if authKey, err = v.GetKeyByAuthID(tt.authID); err != nil || !bytes.Equal(authKey, tt.authKey[:]) {
+			t.Fatalf("the key of auth id %d is not found: %v", tt.authID, err)
+		}
+	}
+	if _, _, err := v.GetClusterKey(3); err != _errors.ErrNoAuthKey {
+		t.Fatalf("cluster 3 has a key: %v", err)
+	}
+	if _, err := v.GetKeyByAuthID(0); err != _errors.ErrNoAuthKey {
+		t.Fatalf("auth id 0 has a key: %v", err)
+	}
+
+	// a replaced key is not appended
+	replacedID, replacedKey := testClusterKey(3)
+	v.SetClusterKey(2, replacedID, replacedKey)
+	if authID, _, _ := v.GetClusterKey(2); authID != replacedID || len(v.Clusters) != 1 {
+		t.Fatalf("the replaced key of cluster 2 is %d in %d clusters", authID, len(v.Clusters))
+	}
+	v.RemoveClusterKey(2)
+	v.RemoveClusterKey(DefaultClusterID)
+	if len(v.Clusters) != 0 || v.AuthID != 0 {
+		t.Fatal("the keys are not removed")
+	}
+	if _, err := v.GetKeyByAuthID(replacedID); err != _errors.ErrNoAuthKey {
+		t.Fatalf("the removed key is found: %v", err)
+	}
+}
+
+func TestExportImportClusterKey(t *testing.T) {
+	src := &RiverConnection{clock: new(clock)}
+	authID, authKey := testClusterKey(1)
+	src.SetClusterKey(DefaultClusterID, authID, authKey)
+	data, err := src.ExportClusterKey(DefaultClusterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.ExportClusterKey(2); err != _errors.ErrNoAuthKey {
+		t.Fatalf("the key of cluster 2 is exported: %v", err)
+	}
+
+	dst := &RiverConnection{clock: new(clock)}
+	if err = dst.ImportClusterKey(4, data); err != nil {
+		t.Fatal(err)
+	}
+	if id, k, err := dst.GetClusterKey(4); err != nil || id != authID || !bytes.Equal(k, authKey[:]) {
+		t.Fatalf("the imported key of cluster 4 is %d %v", id, err)
+	}
+
+	otherID, _ := testClusterKey(2)
+	tests := []struct {
+		name   string
+		authID string
+		err    error
+	}{
+		{"another auth id", strconv.FormatInt(otherID, 10), _errors.ErrAuthIDMismatch},
+		{"zero auth id", "0", _errors.ErrNoAuthKey},
+	}
+	for _, tt := range tests {
+		ck := ClusterKeyJS{AuthID: tt.authID, AuthKey: legacyAuthKey(authKey)}
+		data, _ := ck.MarshalJSON()
+		if err := dst.ImportClusterKey(5, data); err != tt.err {
+			t.Errorf("%s: imported by %v", tt.name, err)
+		}
+	}
+	for _, data := range []string{`{"AuthID":"x"}`, `not json`} {
+		if err := dst.ImportClusterKey(5, []byte(data)); err == nil {
+			t.Errorf("%s is imported", data)
+		}
+	}
+	if _, _, err := dst.GetClusterKey(5); err != _errors.ErrNoAuthKey {
+		t.Fatalf("a rejected key is stored: %v", err)
+	}
+}
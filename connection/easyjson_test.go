@@ -0,0 +1,124 @@
+package river_conn
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// easyjsonSample
+// A value which JSON is kept in testdata/easyjson as easyjson wrote it, decoded is a pointer to the zero
+// value of its type. jsonx writes the version 1 keys as numbers on purpose, so their output is not compared.
+type easyjsonSample struct {
+	name    string
+	value   interface{ MarshalJSON() ([]byte, error) }
+	decoded interface{ UnmarshalJSON([]byte) error }
+	encodes bool
+}
+
+// easyjsonText has the characters which are escaped
+const easyjsonText = "quote \" backslash \\ <tag> & amp \u2028 \u2029 \x01\t\n\r سلام 😀 /"
+
+func easyjsonBytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func easyjsonSamples() []easyjsonSample {
+	var key, otherKey [256]byte
+	copy(key[:], easyjsonBytes(256))
+	for i := range otherKey {
+		otherKey[i] = byte(255 - i)
+	}
+	return []easyjsonSample{
+		{"ServerKeys", ServerKeys{
+			PublicKeys: []publicKey{
+				{N: "25195908475657893494027183240048398571429282126204032027777137836043662020707595556264018525880784406918290641249515082189298559149176184502808489120072844992687392807287776735971418347270261896375014971824691165077613379859095700097330459748808428401797429100642458691817195118746121515172654632282216869987549182422433637259085141865462043576798423387184774447920739934236584823824281198163815010674810451660377306056201619676256133844143603833904414952634432190114657544454178424020924616515723350778707749817125772467962926386356373289912154831438167899885040445364023527381951378636564391212010397122822120720357", FingerPrint: math.MinInt64, E: math.MaxUint32, Padding: PaddingOAEPSHA256},
+				{},
+			},
+			DHGroups:   []dHGroup{{Prime: "ffffffff", Gen: math.MinInt32, FingerPrint: math.MaxInt64}},
+			ECDHGroups: []ecdhGroup{{Curve: easyjsonText, FingerPrint: -1}},
+		}, &ServerKeys{}, true},
+		{"ServerKeysEmpty", ServerKeys{}, &ServerKeys{}, true},
+		{"ServerKeysEmptySlices", ServerKeys{PublicKeys: []publicKey{}, DHGroups: []dHGroup{}, ECDHGroups: []ecdhGroup{}},
+			&ServerKeys{}, true},
+		{"SignedServerKeys", SignedServerKeys{Env: easyjsonText, ExpiresAt: -5, Keys: easyjsonBytes(100), Signature: []byte{}},
+			&SignedServerKeys{}, true},
+		{"SignedServerKeysEmpty", SignedServerKeys{}, &SignedServerKeys{}, true},
+		{"ClusterKeyJS", ClusterKeyJS{ClusterID: math.MinInt32, AuthID: "-9223372036854775808", AuthKey: legacyAuthKey(key)},
+			&ClusterKeyJS{}, false},
+		{"HandshakeJS", HandshakeJS{
+			Step:          2,
+			ClusterID:     -1,
+			TempTTL:       math.MaxInt32,
+			ClientNonce:   "18446744073709551615",
+			ServerNonce:   "0",
+			DHFingerPrint: "-42",
+			SecretNonce:   easyjsonBytes(32),
+			Request:       []byte{},
+			CreatedAt:     1700000000,
+		}, &HandshakeJS{}, true},
+		{"RiverConnectionJS", RiverConnectionJS{
+			AuthID:    "1234",
+			AuthKey:   legacyAuthKey(key),
+			UserID:    "-1",
+			Username:  easyjsonText,
+			Phone:     "+98 912",
+			FirstName: "سلام",
+			Clusters: []ClusterKeyJS{
+				{ClusterID: 2, AuthID: "5", AuthKey: legacyAuthKey(otherKey)},
+				{ClusterID: 3, AuthID: "6"},
+			},
+		}, &RiverConnectionJS{}, false},
+		{"ServerSaltJS", ServerSaltJS{Salt: "-1", ValidSince: math.MaxInt64}, &ServerSaltJS{}, true},
+		{"ClusterKeyV2", ClusterKeyV2{ClusterID: 7, AuthID: "8", AuthKey: key[:]}, &ClusterKeyV2{}, true},
+		{"RiverConnectionV2", RiverConnectionV2{
+			Version:   ConnInfoVersion2,
+			AuthID:    "-9223372036854775808",
+			AuthKey:   key[:],
+			UserID:    "9223372036854775807",
+			Username:  easyjsonText,
+			Phone:     "",
+			FirstName: "a",
+			LastName:  "b",
+			DiffTime:  -3600000,
+			SessionID: "77",
+			Salts:     []ServerSaltJS{{Salt: "1", ValidSince: 2}, {}},
+			Clusters:  []ClusterKeyV2{{ClusterID: 2, AuthID: "5", AuthKey: otherKey[:]}},
+		}, &RiverConnectionV2{}, true},
+		{"RiverConnectionV2Empty", RiverConnectionV2{}, &RiverConnectionV2{}, true},
+	}
+}
+
+// TestEasyjsonEquivalence checks jsonx writes the JSON which easyjson wrote for the same values, and
+// decodes it back to them
+func TestEasyjsonEquivalence(t *testing.T) {
+	for _, s := range easyjsonSamples() {
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", "easyjson", s.name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.encodes {
+			data, err := s.value.MarshalJSON()
+			if err != nil {
+				t.Fatalf("%s: %v", s.name, err)
+			}
+			if !bytes.Equal(data, expected) {
+				t.Errorf("%s is written as\n%s\neasyjson wrote\n%s", s.name, data, expected)
+			}
+		}
+		if err = s.decoded.UnmarshalJSON(expected); err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		if decoded := reflect.ValueOf(s.decoded).Elem().Interface(); !reflect.DeepEqual(decoded, s.value) {
+			t.Errorf("%s is decoded as\n%#v\nexpected\n%#v", s.name, decoded, s.value)
+		}
+	}
+}
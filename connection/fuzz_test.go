@@ -0,0 +1,162 @@
+package river_conn
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// update writes the golden files and the fuzz corpus again
+var update = flag.Bool("update", false, "write the golden files and the fuzz corpus again")
+
+// testRootKey signs the server keys of the tests, testExpiresAt is far in the future
+var testRootKey = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+
+const testExpiresAt = 4102444800
+
+// setTestRootKey sets the root key and the environment of the server keys of the tests until t ends
+func setTestRootKey(t testing.TB, dev bool) {
+	oldKey, oldEnv, oldDev := rootPublicKey, buildEnv, devMode
+	t.Cleanup(func() {
+		rootPublicKey, buildEnv, devMode = oldKey, oldEnv, oldDev
+	})
+	rootPublicKey = base64.StdEncoding.EncodeToString(testRootKey.Public().(ed25519.PublicKey))
+	buildEnv = EnvStaging
+	devMode = strconv.FormatBool(dev)
+}
+
+// The fuzz targets fail on ErrMalformedInput, it is a panic which is recovered
+func FuzzLoadServerKeys(f *testing.F) {
+	// dev mode lets the unsigned keys reach the parser, a fuzzed bundle is never signed
+	setTestRootKey(f, true)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		keys := ServerKeys{}
+		if err := keys.LoadServerKeys(data); err == _errors.ErrMalformedInput {
+			t.Fatal("LoadServerKeys panicked")
+		}
+	})
+}
+
+// FuzzConnInfoLoad checks that the loaded connection info is saved and loaded again as it is
+func FuzzConnInfoLoad(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v := &RiverConnection{clock: new(clock)}
+		err := v.Load(string(data))
+		if err == _errors.ErrMalformedInput {
+			t.Fatal("Load panicked")
+		}
+		if err != nil {
+			return
+		}
+		saved, err := v.marshalConnInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := &RiverConnection{clock: new(clock)}
+		if err = w.Load(string(saved)); err != nil {
+			t.Fatalf("saved connection info is not loaded: %v\n%s", err, saved)
+		}
+		if again, _ := w.marshalConnInfo(); !bytes.Equal(saved, again) {
+			t.Fatalf("saved connection info changed by loading it:\n%s\n%s", saved, again)
+		}
+	})
+}
+
+// testServerKeys returns the plain server keys of the tests
+func testServerKeys() []byte {
+	return []byte(`{"PublicKeys":[{"N":"` + strings.Repeat("7", 600) + `","FingerPrint":1001,"E":65537,` +
+		`"Padding":"OAEP-SHA256"}],"DHGroups":[{"Prime":"` + strings.Repeat("F", 512) + `","Gen":2,` +
+		`"FingerPrint":2001}],"ECDHGroups":[{"Curve":"X25519","FingerPrint":3001}]}`)
+}
+
+// testSignedServerKeys returns the server keys of the tests signed by testRootKey
+func testSignedServerKeys(t testing.TB) []byte {
+	bundle := SignedServerKeys{
+		Env:       EnvStaging,
+		ExpiresAt: testExpiresAt,
+		Keys:      testServerKeys(),
+	}
+	bundle.Sign(testRootKey)
+	data, err := bundle.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testAuthKey returns an auth key which every byte of is seed plus its index
+func testAuthKey(seed byte) [256]byte {
+	var k [256]byte
+	for i := range k {
+		k[i] = seed + byte(i)
+	}
+	return k
+}
+
+// TestWriteCorpus writes the seeds of the fuzz targets: the signed and the plain server keys, and the
+// connection info of each version
+func TestWriteCorpus(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to write the corpus again")
+	}
+	writeCorpusFile(t, "FuzzLoadServerKeys", "signed", testSignedServerKeys(t))
+	writeCorpusFile(t, "FuzzLoadServerKeys", "plain", testServerKeys())
+
+	v1 := RiverConnectionJS{
+		AuthID:    "1234567890123",
+		AuthKey:   legacyAuthKey(testAuthKey(1)),
+		UserID:    "42",
+		Username:  "river",
+		Phone:     "989121234567",
+		FirstName: "River",
+		LastName:  "Test",
+		Clusters:  []ClusterKeyJS{{ClusterID: 2, AuthID: "987654321", AuthKey: legacyAuthKey(testAuthKey(2))}},
+	}
+	v1Data, err := v1.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCorpusFile(t, "FuzzConnInfoLoad", "v1", v1Data)
+	// easyjson wrote the version 1 keys in base64
+	numbers := make([]string, 256)
+	for i, b := range v1.AuthKey {
+		numbers[i] = strconv.Itoa(int(b))
+	}
+	v1Base64 := strings.Replace(string(v1Data), "["+strings.Join(numbers, ",")+"]",
+		strconv.Quote(base64.StdEncoding.EncodeToString(v1.AuthKey[:])), 1)
+	writeCorpusFile(t, "FuzzConnInfoLoad", "v1-base64", []byte(v1Base64))
+
+	v := &RiverConnection{clock: new(clock)}
+	if err = v.Load(string(v1Data)); err != nil {
+		t.Fatal(err)
+	}
+	v.DiffTime = -1500
+	v.SessionID = 5555
+	v.Salts = []ServerSalt{{Salt: 777, ValidSince: 1600000000}}
+	v2Data, err := v.marshalConnInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCorpusFile(t, "FuzzConnInfoLoad", "v2", v2Data)
+}
+
+func writeCorpusFile(t *testing.T, target, name string, data []byte) {
+	dir := filepath.Join("testdata", "fuzz", target)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", data)
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
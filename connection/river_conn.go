@@ -1,22 +1,24 @@
 package river_conn
 
 import (
-	"fmt"
 	_errors "git.ronaksoft.com/river/web-wasm/errors"
 	"strconv"
-	"syscall/js"
-	"time"
 )
 
-// easyjson:json
+// RSA paddings of the public keys, keys with no padding are PKCS#1 v1.5 keys of the old servers
+const (
+	PaddingPKCS1v15   = ""
+	PaddingOAEPSHA256 = "OAEP-SHA256"
+)
+
 // publicKey
 type publicKey struct {
 	N           string
 	FingerPrint int64
 	E           uint32
+	Padding     string
 }
 
-// easyjson:json
 // dHGroup
 type dHGroup struct {
 	Prime       string
@@ -24,11 +26,26 @@ type dHGroup struct {
 	FingerPrint int64
 }
 
-// easyjson:json
+// ecdhGroup
+type ecdhGroup struct {
+	Curve       string
+	FingerPrint int64
+}
+
 // ServerKeys
 type ServerKeys struct {
 	PublicKeys []publicKey
 	DHGroups   []dHGroup
+	ECDHGroups []ecdhGroup
+}
+
+// SignedServerKeys
+// Keys is the JSON encoded ServerKeys, which is signed by the root key along with Env and ExpiresAt
+type SignedServerKeys struct {
+	Env       string
+	ExpiresAt int64
+	Keys      []byte
+	Signature []byte
 }
 
 // getPublicKey
@@ -51,9 +68,56 @@ func (v *ServerKeys) GetDhGroup(keyFP int64) (dHGroup, error) {
 	return dHGroup{}, _errors.ErrNotFound
 }
 
-// easyjson:json
+// getEcdhGroup
+func (v *ServerKeys) GetEcdhGroup(keyFP int64) (ecdhGroup, error) {
+	for _, g := range v.ECDHGroups {
+		if g.FingerPrint == keyFP {
+			return g, nil
+		}
+	}
+	return ecdhGroup{}, _errors.ErrNotFound
+}
+
+// ClusterKey
+// Auth key which is negotiated with a cluster other than the default one
+type ClusterKey struct {
+	ClusterID int32
+	AuthID    int64
+	AuthKey   [256]byte
+}
+
+// ClusterKeyJS
+type ClusterKeyJS struct {
+	ClusterID int32
+	AuthID    string
+	AuthKey   legacyAuthKey
+}
+
+// HandshakeJS
+// State of an unfinished handshake, which is persisted so it could be resumed after reload
+type HandshakeJS struct {
+	Step          int
+	ClusterID     int32
+	TempTTL       int32
+	ClientNonce   string
+	ServerNonce   string
+	DHFingerPrint string
+	PrivateKey    []byte
+	SecretNonce   []byte
+	Request       []byte
+	CreatedAt     int64
+}
+
+// storageKeyPrefix
+// Connection info of each account is persisted under its own key
+const (
+	storageKeyPrefix          = "river.connInfo."
+	handshakeStorageKeyPrefix = "river.handshake."
+)
+
 // RiverConnection
 type RiverConnection struct {
+	handle    string
 	AuthID    int64
 	AuthKey   [256]byte
 	UserID    int64
@@ -61,75 +125,73 @@ type RiverConnection struct {
 	Phone     string
 	FirstName string
 	LastName  string
-	DiffTime  int64
+	DiffTime  int64 // milliseconds
+	SessionID int64
+	Salts     []ServerSalt
+	Clusters  []ClusterKey
+	clock     *clock
 }
 
-// easyjson:json
-// RiverConnection
+// RiverConnectionJS
+// Version 1 of the persisted connection info, it is only loaded to be migrated
 type RiverConnectionJS struct {
 	AuthID    string
-	AuthKey   [256]byte
+	AuthKey   legacyAuthKey
 	UserID    string
 	Username  string
 	Phone     string
 	FirstName string
 	LastName  string
+	Clusters  []ClusterKeyJS
 }
 
 // NewRiverConnection
-func NewRiverConnection(connInfo string) (rc *RiverConnection, err error) {
+func NewRiverConnection(handle, connInfo string) (rc *RiverConnection, err error) {
 	rc = new(RiverConnection)
+	rc.handle = handle
+	rc.clock = new(clock)
 	err = rc.Load(connInfo)
-	if err != nil {
-		return
-	}
-
-	rc.DiffTime = 0
 	return
 }
 
 // Save
 func (v *RiverConnection) Save() {
-	var vv = RiverConnectionJS{
-		AuthKey:   v.AuthKey,
-		AuthID:    strconv.FormatInt(v.AuthID, 10),
-		Username:  v.Username,
-		FirstName: v.FirstName,
-		LastName:  v.LastName,
-		Phone:     v.Phone,
-		UserID:    strconv.FormatInt(v.UserID, 10),
-	}
-
-	if bytes, err := vv.MarshalJSON(); err != nil {
-		fmt.Println(err.Error(), "RiverConnection::Save")
+	if bytes, err := v.marshalConnInfo(); err != nil {
+		println(err.Error(), "RiverConnection::Save")
 	} else {
-		//fmt.Println(bytes)
-		js.Global().Call("jsSave", string(bytes))
+		storage(string(bytes), v.StorageKey())
 	}
 }
 
-// Load
+// SaveHandshake persists the state of the unfinished handshake of the account, empty data removes it
+func SaveHandshake(handle string, id int64, data []byte) {
+	storage(string(data), handshakeStorageKeyPrefix+handle+"."+strconv.FormatInt(id, 10))
+}
+
+// maxConnInfoSize is the largest connection info which is loaded
+const maxConnInfoSize = 64 * 1024
+
+// Load loads the connection info of any version, the older versions are saved again in the current version
 func (v *RiverConnection) Load(connInfo string) error {
-	var vv = RiverConnectionJS{}
-	if err := vv.UnmarshalJSON([]byte(connInfo)); err != nil {
-		fmt.Println(err.Error(), "RiverConnection::Load")
+	if len(connInfo) > maxConnInfoSize {
+		return _errors.ErrInputTooLarge
+	}
+	migrated, err := v.unmarshalConnInfo([]byte(connInfo))
+	if err != nil {
 		return err
 	}
-
-	v.AuthKey = vv.AuthKey
-	v.AuthID, _ = strconv.ParseInt(vv.AuthID, 10, 64)
-	v.FirstName = vv.FirstName
-	v.LastName = vv.LastName
-	v.Phone = vv.Phone
-	v.Username = vv.Username
-	v.UserID, _ = strconv.ParseInt(vv.UserID, 10, 64)
+	if migrated && v.AuthID != 0 {
+		v.Save()
+	}
 	return nil
 }
 
-func (v *RiverConnection) SetServerTime(timestamp int64) {
-	v.DiffTime = timestamp - time.Now().Unix()
+// StorageKey returns the key which connection info of this account is stored with
+func (v *RiverConnection) StorageKey() string {
+	return ConnInfoStorageKey(v.handle)
 }
 
-func (v *RiverConnection) Now() int64 {
-	return time.Now().Unix() + v.DiffTime
+// ConnInfoStorageKey returns the key which connection info of the account is stored with
+func ConnInfoStorageKey(handle string) string {
+	return storageKeyPrefix + handle
 }
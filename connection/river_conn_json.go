@@ -0,0 +1,331 @@
+package river_conn
+
+import (
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+)
+
+// MarshalJSON implements json.Marshaler
+func (v ServerKeys) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *ServerKeys) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v ServerKeys) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("PublicKeys")
+	w.Array(v.PublicKeys == nil, len(v.PublicKeys), func(i int) { v.PublicKeys[i].writeJSON(w) })
+	w.Field("DHGroups")
+	w.Array(v.DHGroups == nil, len(v.DHGroups), func(i int) { v.DHGroups[i].writeJSON(w) })
+	w.Field("ECDHGroups")
+	w.Array(v.ECDHGroups == nil, len(v.ECDHGroups), func(i int) { v.ECDHGroups[i].writeJSON(w) })
+	w.ObjectEnd()
+}
+
+func (v *ServerKeys) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "PublicKeys":
+			v.PublicKeys = []publicKey{}
+			l.Array(func() {
+				x := publicKey{}
+				x.readJSON(l)
+				v.PublicKeys = append(v.PublicKeys, x)
+			})
+		case "DHGroups":
+			v.DHGroups = []dHGroup{}
+			l.Array(func() {
+				x := dHGroup{}
+				x.readJSON(l)
+				v.DHGroups = append(v.DHGroups, x)
+			})
+		case "ECDHGroups":
+			v.ECDHGroups = []ecdhGroup{}
+			l.Array(func() {
+				x := ecdhGroup{}
+				x.readJSON(l)
+				v.ECDHGroups = append(v.ECDHGroups, x)
+			})
+		default:
+			l.Skip()
+		}
+	})
+}
+
+func (v publicKey) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("N")
+	w.String(v.N)
+	w.Field("FingerPrint")
+	w.Int64(v.FingerPrint)
+	w.Field("E")
+	w.Uint64(uint64(v.E))
+	w.Field("Padding")
+	w.String(v.Padding)
+	w.ObjectEnd()
+}
+
+func (v *publicKey) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "N":
+			v.N = l.String()
+		case "FingerPrint":
+			v.FingerPrint = l.Int64()
+		case "E":
+			v.E = l.Uint32()
+		case "Padding":
+			v.Padding = l.String()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+func (v dHGroup) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Prime")
+	w.String(v.Prime)
+	w.Field("Gen")
+	w.Int64(int64(v.Gen))
+	w.Field("FingerPrint")
+	w.Int64(v.FingerPrint)
+	w.ObjectEnd()
+}
+
+func (v *dHGroup) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Prime":
+			v.Prime = l.String()
+		case "Gen":
+			v.Gen = l.Int32()
+		case "FingerPrint":
+			v.FingerPrint = l.Int64()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+func (v ecdhGroup) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Curve")
+	w.String(v.Curve)
+	w.Field("FingerPrint")
+	w.Int64(v.FingerPrint)
+	w.ObjectEnd()
+}
+
+func (v *ecdhGroup) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Curve":
+			v.Curve = l.String()
+		case "FingerPrint":
+			v.FingerPrint = l.Int64()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v SignedServerKeys) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Env")
+	w.String(v.Env)
+	w.Field("ExpiresAt")
+	w.Int64(v.ExpiresAt)
+	w.Field("Keys")
+	w.Base64(v.Keys)
+	w.Field("Signature")
+	w.Base64(v.Signature)
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *SignedServerKeys) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Env":
+			v.Env = l.String()
+		case "ExpiresAt":
+			v.ExpiresAt = l.Int64()
+		case "Keys":
+			v.Keys = l.Bytes()
+		case "Signature":
+			v.Signature = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v ClusterKeyJS) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *ClusterKeyJS) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v ClusterKeyJS) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("ClusterID")
+	w.Int64(int64(v.ClusterID))
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("AuthKey")
+	v.AuthKey.writeJSON(w)
+	w.ObjectEnd()
+}
+
+func (v *ClusterKeyJS) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "ClusterID":
+			v.ClusterID = l.Int32()
+		case "AuthID":
+			v.AuthID = l.String()
+		case "AuthKey":
+			v.AuthKey.readJSON(l)
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v HandshakeJS) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Step")
+	w.Int64(int64(v.Step))
+	w.Field("ClusterID")
+	w.Int64(int64(v.ClusterID))
+	w.Field("TempTTL")
+	w.Int64(int64(v.TempTTL))
+	w.Field("ClientNonce")
+	w.String(v.ClientNonce)
+	w.Field("ServerNonce")
+	w.String(v.ServerNonce)
+	w.Field("DHFingerPrint")
+	w.String(v.DHFingerPrint)
+	w.Field("PrivateKey")
+	w.Base64(v.PrivateKey)
+	w.Field("SecretNonce")
+	w.Base64(v.SecretNonce)
+	w.Field("Request")
+	w.Base64(v.Request)
+	w.Field("CreatedAt")
+	w.Int64(v.CreatedAt)
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *HandshakeJS) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Step":
+			v.Step = l.Int()
+		case "ClusterID":
+			v.ClusterID = l.Int32()
+		case "TempTTL":
+			v.TempTTL = l.Int32()
+		case "ClientNonce":
+			v.ClientNonce = l.String()
+		case "ServerNonce":
+			v.ServerNonce = l.String()
+		case "DHFingerPrint":
+			v.DHFingerPrint = l.String()
+		case "PrivateKey":
+			v.PrivateKey = l.Bytes()
+		case "SecretNonce":
+			v.SecretNonce = l.Bytes()
+		case "Request":
+			v.Request = l.Bytes()
+		case "CreatedAt":
+			v.CreatedAt = l.Int64()
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v RiverConnectionJS) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("AuthKey")
+	v.AuthKey.writeJSON(&w)
+	w.Field("UserID")
+	w.String(v.UserID)
+	w.Field("Username")
+	w.String(v.Username)
+	w.Field("Phone")
+	w.String(v.Phone)
+	w.Field("FirstName")
+	w.String(v.FirstName)
+	w.Field("LastName")
+	w.String(v.LastName)
+	w.Field("Clusters")
+	w.Array(v.Clusters == nil, len(v.Clusters), func(i int) { v.Clusters[i].writeJSON(&w) })
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *RiverConnectionJS) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "AuthID":
+			v.AuthID = l.String()
+		case "AuthKey":
+			v.AuthKey.readJSON(l)
+		case "UserID":
+			v.UserID = l.String()
+		case "Username":
+			v.Username = l.String()
+		case "Phone":
+			v.Phone = l.String()
+		case "FirstName":
+			v.FirstName = l.String()
+		case "LastName":
+			v.LastName = l.String()
+		case "Clusters":
+			v.Clusters = []ClusterKeyJS{}
+			l.Array(func() {
+				x := ClusterKeyJS{}
+				x.readJSON(l)
+				v.Clusters = append(v.Clusters, x)
+			})
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
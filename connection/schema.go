@@ -0,0 +1,237 @@
+package river_conn
+
+import (
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+	"strconv"
+	"sync/atomic"
+)
+
+// Versions of the persisted connection info. Version 1 is RiverConnectionJS which has no Version field,
+// it is migrated to the current version when it is loaded.
+const (
+	ConnInfoVersion1       = 1
+	ConnInfoVersion2       = 2
+	CurrentConnInfoVersion = ConnInfoVersion2
+)
+
+// connInfoHeader
+// Only the version is read to select the schema
+type connInfoHeader struct {
+	Version int
+}
+
+// ServerSaltJS
+type ServerSaltJS struct {
+	Salt       string
+	ValidSince int64
+}
+
+// ClusterKeyV2
+type ClusterKeyV2 struct {
+	ClusterID int32
+	AuthID    string
+	AuthKey   []byte
+}
+
+// RiverConnectionV2
+// Keys are encoded in base64 and the optional fields are omitted if they are not set
+type RiverConnectionV2 struct {
+	Version   int
+	AuthID    string
+	AuthKey   []byte
+	UserID    string
+	Username  string
+	Phone     string
+	FirstName string
+	LastName  string
+	DiffTime  int64          `json:",omitempty"`
+	SessionID string         `json:",omitempty"`
+	Salts     []ServerSaltJS `json:",omitempty"`
+	Clusters  []ClusterKeyV2 `json:",omitempty"`
+}
+
+// ServerSalt
+type ServerSalt struct {
+	Salt       int64
+	ValidSince int64
+}
+
+// legacyAuthKey
+// Version 1 keys are stored by the app as arrays of 256 numbers, the base64 strings which easyjson
+// wrote are read too
+type legacyAuthKey [256]byte
+
+func (k legacyAuthKey) writeJSON(w *jsonx.Writer) {
+	w.Array(false, len(k), func(i int) { w.Int64(int64(k[i])) })
+}
+
+func (k *legacyAuthKey) readJSON(l *jsonx.Lexer) {
+	if !l.IsDelim('[') {
+		copy(k[:], l.Bytes())
+		return
+	}
+	n := 0
+	l.Array(func() {
+		b := l.Uint8()
+		if n < len(k) {
+			k[n] = b
+		}
+		n++
+	})
+	if n != 0 && n != len(k) {
+		l.AddError(_errors.ErrInvalidAuthKey)
+	}
+}
+
+// marshalConnInfo encodes the connection info in the current version
+func (v *RiverConnection) marshalConnInfo() ([]byte, error) {
+	vv := RiverConnectionV2{
+		Version:   CurrentConnInfoVersion,
+		AuthID:    strconv.FormatInt(v.AuthID, 10),
+		AuthKey:   v.AuthKey[:],
+		UserID:    strconv.FormatInt(v.UserID, 10),
+		Username:  v.Username,
+		Phone:     v.Phone,
+		FirstName: v.FirstName,
+		LastName:  v.LastName,
+		DiffTime:  atomic.LoadInt64(&v.DiffTime),
+	}
+	if v.SessionID != 0 {
+		vv.SessionID = strconv.FormatInt(v.SessionID, 10)
+	}
+	for _, s := range v.Salts {
+		vv.Salts = append(vv.Salts, ServerSaltJS{
+			Salt:       strconv.FormatInt(s.Salt, 10),
+			ValidSince: s.ValidSince,
+		})
+	}
+	for _, ck := range v.Clusters {
+		vv.Clusters = append(vv.Clusters, ClusterKeyV2{
+			ClusterID: ck.ClusterID,
+			AuthID:    strconv.FormatInt(ck.AuthID, 10),
+			AuthKey:   ck.AuthKey[:],
+		})
+	}
+	return vv.MarshalJSON()
+}
+
+// unmarshalConnInfo decodes the connection info of any version, migrated is true if it is not
+// in the current version
+func (v *RiverConnection) unmarshalConnInfo(data []byte) (migrated bool, err error) {
+	defer recoverMalformed(&err)
+	h := connInfoHeader{}
+	if err = h.UnmarshalJSON(data); err != nil {
+		return
+	}
+	switch h.Version {
+	case 0, ConnInfoVersion1:
+		return true, v.unmarshalConnInfoV1(data)
+	case ConnInfoVersion2:
+		return false, v.unmarshalConnInfoV2(data)
+	default:
+		return false, _errors.ErrConnInfoVersion
+	}
+}
+
+func (v *RiverConnection) unmarshalConnInfoV1(data []byte) error {
+	var vv = RiverConnectionJS{}
+	if err := vv.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	v.AuthKey = [256]byte(vv.AuthKey)
+	v.AuthID, _ = strconv.ParseInt(vv.AuthID, 10, 64)
+	v.FirstName = vv.FirstName
+	v.LastName = vv.LastName
+	v.Phone = vv.Phone
+	v.Username = vv.Username
+	v.UserID, _ = strconv.ParseInt(vv.UserID, 10, 64)
+	v.Clusters = v.Clusters[:0]
+	for _, ck := range vv.Clusters {
+		authID, _ := strconv.ParseInt(ck.AuthID, 10, 64)
+		v.Clusters = append(v.Clusters, ClusterKey{
+			ClusterID: ck.ClusterID,
+			AuthID:    authID,
+			AuthKey:   [256]byte(ck.AuthKey),
+		})
+	}
+	return nil
+}
+
+func (v *RiverConnection) unmarshalConnInfoV2(data []byte) (err error) {
+	var vv = RiverConnectionV2{}
+	if err = vv.UnmarshalJSON(data); err != nil {
+		return
+	}
+
+	if err = copyAuthKey(&v.AuthKey, vv.AuthKey); err != nil {
+		return
+	}
+	if v.AuthID, err = parseOptionalInt(vv.AuthID); err != nil {
+		return
+	}
+	if v.UserID, err = parseOptionalInt(vv.UserID); err != nil {
+		return
+	}
+	if v.SessionID, err = parseOptionalInt(vv.SessionID); err != nil {
+		return
+	}
+	v.FirstName = vv.FirstName
+	v.LastName = vv.LastName
+	v.Phone = vv.Phone
+	v.Username = vv.Username
+	atomic.StoreInt64(&v.DiffTime, vv.DiffTime)
+	v.Salts = v.Salts[:0]
+	for _, s := range vv.Salts {
+		salt, err := strconv.ParseInt(s.Salt, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.Salts = append(v.Salts, ServerSalt{
+			Salt:       salt,
+			ValidSince: s.ValidSince,
+		})
+	}
+	v.Clusters = v.Clusters[:0]
+	for _, ck := range vv.Clusters {
+		k := ClusterKey{
+			ClusterID: ck.ClusterID,
+		}
+		if k.AuthID, err = strconv.ParseInt(ck.AuthID, 10, 64); err != nil {
+			return
+		}
+		if err = copyAuthKey(&k.AuthKey, ck.AuthKey); err != nil {
+			return
+		}
+		v.Clusters = append(v.Clusters, k)
+	}
+	return nil
+}
+
+// recoverMalformed turns a panic of parsing the untrusted input into ErrMalformedInput
+func recoverMalformed(err *error) {
+	if recover() != nil {
+		*err = _errors.ErrMalformedInput
+	}
+}
+
+// copyAuthKey accepts an empty key for the accounts which have no auth key yet
+func copyAuthKey(dst *[256]byte, src []byte) error {
+	switch len(src) {
+	case 0:
+		*dst = [256]byte{}
+	case len(dst):
+		copy(dst[:], src)
+	default:
+		return _errors.ErrInvalidAuthKey
+	}
+	return nil
+}
+
+func parseOptionalInt(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
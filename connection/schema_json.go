@@ -0,0 +1,181 @@
+package river_conn
+
+import (
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+)
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *connInfoHeader) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Version":
+			v.Version = l.Int()
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v ServerSaltJS) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *ServerSaltJS) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v ServerSaltJS) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Salt")
+	w.String(v.Salt)
+	w.Field("ValidSince")
+	w.Int64(v.ValidSince)
+	w.ObjectEnd()
+}
+
+func (v *ServerSaltJS) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Salt":
+			v.Salt = l.String()
+		case "ValidSince":
+			v.ValidSince = l.Int64()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v ClusterKeyV2) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *ClusterKeyV2) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v ClusterKeyV2) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("ClusterID")
+	w.Int64(int64(v.ClusterID))
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("AuthKey")
+	w.Base64(v.AuthKey)
+	w.ObjectEnd()
+}
+
+func (v *ClusterKeyV2) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "ClusterID":
+			v.ClusterID = l.Int32()
+		case "AuthID":
+			v.AuthID = l.String()
+		case "AuthKey":
+			v.AuthKey = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v RiverConnectionV2) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Version")
+	w.Int64(int64(v.Version))
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("AuthKey")
+	w.Base64(v.AuthKey)
+	w.Field("UserID")
+	w.String(v.UserID)
+	w.Field("Username")
+	w.String(v.Username)
+	w.Field("Phone")
+	w.String(v.Phone)
+	w.Field("FirstName")
+	w.String(v.FirstName)
+	w.Field("LastName")
+	w.String(v.LastName)
+	if v.DiffTime != 0 {
+		w.Field("DiffTime")
+		w.Int64(v.DiffTime)
+	}
+	if v.SessionID != "" {
+		w.Field("SessionID")
+		w.String(v.SessionID)
+	}
+	if len(v.Salts) != 0 {
+		w.Field("Salts")
+		w.Array(false, len(v.Salts), func(i int) { v.Salts[i].writeJSON(&w) })
+	}
+	if len(v.Clusters) != 0 {
+		w.Field("Clusters")
+		w.Array(false, len(v.Clusters), func(i int) { v.Clusters[i].writeJSON(&w) })
+	}
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *RiverConnectionV2) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Version":
+			v.Version = l.Int()
+		case "AuthID":
+			v.AuthID = l.String()
+		case "AuthKey":
+			v.AuthKey = l.Bytes()
+		case "UserID":
+			v.UserID = l.String()
+		case "Username":
+			v.Username = l.String()
+		case "Phone":
+			v.Phone = l.String()
+		case "FirstName":
+			v.FirstName = l.String()
+		case "LastName":
+			v.LastName = l.String()
+		case "DiffTime":
+			v.DiffTime = l.Int64()
+		case "SessionID":
+			v.SessionID = l.String()
+		case "Salts":
+			v.Salts = []ServerSaltJS{}
+			l.Array(func() {
+				x := ServerSaltJS{}
+				x.readJSON(l)
+				v.Salts = append(v.Salts, x)
+			})
+		case "Clusters":
+			v.Clusters = []ClusterKeyV2{}
+			l.Array(func() {
+				x := ClusterKeyV2{}
+				x.readJSON(l)
+				v.Clusters = append(v.Clusters, x)
+			})
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
@@ -0,0 +1,123 @@
+package river_conn
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// readGolden returns the golden file of name, with -update it writes data to the file first
+func readGolden(t *testing.T, name string, data []byte) []byte {
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.TrimSuffix(golden, []byte{'\n'})
+}
+
+// TestConnInfoMigration loads the version 1 connection info which the app stored, it must be saved
+// as testdata/v2.json
+func TestConnInfoMigration(t *testing.T) {
+	v1, err := ioutil.ReadFile(filepath.Join("testdata", "v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []string
+	SetStorage(func(data, key string) { saved = append(saved, key, data) })
+	defer SetStorage(defaultStorage)
+	v, err := NewRiverConnection("migration", string(v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[0] != v.StorageKey() {
+		t.Fatalf("the migrated connection info is saved as %q", saved)
+	}
+	v2 := readGolden(t, "v2.json", []byte(saved[1]))
+	if saved[1] != string(v2) {
+		t.Fatalf("the migrated connection info is\n%s\nexpected\n%s", saved[1], v2)
+	}
+
+	// the keys are decoded by encoding/json too, the numbers of version 1 must be the base64 of version 2
+	type cluster struct {
+		ClusterID int32
+		AuthID    string
+		AuthKey   []int
+	}
+	type clusterV2 struct {
+		ClusterID int32
+		AuthID    string
+		AuthKey   []byte
+	}
+	var old struct {
+		AuthID   string
+		AuthKey  []int
+		Clusters []cluster
+	}
+	var migrated struct {
+		Version  int
+		AuthID   string
+		AuthKey  []byte
+		Clusters []clusterV2
+	}
+	if err = json.Unmarshal(v1, &old); err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(v2, &migrated); err != nil {
+		t.Fatal(err)
+	}
+	if migrated.Version != ConnInfoVersion2 || migrated.AuthID != old.AuthID || len(migrated.Clusters) != len(old.Clusters) {
+		t.Fatalf("version 1 %+v is migrated to %+v", old, migrated)
+	}
+	equalKeys := func(numbers []int, key []byte) bool {
+		if len(numbers) != 256 || len(key) != 256 {
+			return false
+		}
+		for i, n := range numbers {
+			if int(key[i]) != n {
+				return false
+			}
+		}
+		return true
+	}
+	if !equalKeys(old.AuthKey, migrated.AuthKey) {
+		t.Fatal("the auth key is changed by the migration")
+	}
+	for i, ck := range old.Clusters {
+		m := migrated.Clusters[i]
+		if m.ClusterID != ck.ClusterID || m.AuthID != ck.AuthID || !equalKeys(ck.AuthKey, m.AuthKey) {
+			t.Fatalf("the key of cluster %d is changed by the migration", ck.ClusterID)
+		}
+	}
+}
+
+// TestConnInfoRoundTrip loads testdata/v2.json, it is not migrated and is saved as it is
+func TestConnInfoRoundTrip(t *testing.T) {
+	v2, err := ioutil.ReadFile(filepath.Join("testdata", "v2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 = bytes.TrimSuffix(v2, []byte{'\n'})
+	v := &RiverConnection{clock: new(clock)}
+	migrated, err := v.unmarshalConnInfo(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated {
+		t.Fatal("version 2 is migrated")
+	}
+	saved, err := v.marshalConnInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(saved, v2) {
+		t.Fatalf("the connection info is saved as\n%s\nexpected\n%s", saved, v2)
+	}
+}
@@ -0,0 +1,107 @@
+package river_conn
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"time"
+)
+
+// These are set at build time by -ldflags "-X", e.g.
+// -X git.ronaksoft.com/river/web-wasm/connection.rootPublicKey=<base64 ed25519 public key>
+// -X git.ronaksoft.com/river/web-wasm/connection.buildEnv=staging
+// -X git.ronaksoft.com/river/web-wasm/connection.devMode=true
+var (
+	rootPublicKey = ""
+	buildEnv      = EnvProduction
+	devMode       = "false"
+)
+
+const (
+	EnvProduction = "prod"
+	EnvStaging    = "staging"
+)
+
+// serverKeysSignaturePrefix separates the signatures of server keys from any other data signed by the root key
+const serverKeysSignaturePrefix = "river-server-keys"
+
+// Limits of the server keys, they are passed by the app which could have them from anywhere
+const (
+	// maxServerKeysSize is the largest JSON of the server keys which is accepted
+	maxServerKeysSize = 64 * 1024
+	// maxServerKeys is the most keys of each kind
+	maxServerKeys = 32
+)
+
+// LoadServerKeys verifies the signed bundle against the root key which is compiled into the binary and
+// fills v with its keys. Unsigned bundles (plain ServerKeys) are only accepted in dev mode.
+func (v *ServerKeys) LoadServerKeys(data []byte) (err error) {
+	if len(data) > maxServerKeysSize {
+		return _errors.ErrInputTooLarge
+	}
+	defer recoverMalformed(&err)
+	bundle := SignedServerKeys{}
+	if err := bundle.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if len(bundle.Signature) == 0 {
+		if devMode != "true" {
+			return _errors.ErrUnsignedServerKeys
+		}
+		return v.unmarshalServerKeys(data)
+	}
+
+	if err := bundle.Verify(time.Now().Unix()); err != nil {
+		return err
+	}
+	return v.unmarshalServerKeys(bundle.Keys)
+}
+
+// unmarshalServerKeys is UnmarshalJSON which rejects too many keys, v is left unchanged if it fails
+func (v *ServerKeys) unmarshalServerKeys(data []byte) error {
+	keys := ServerKeys{}
+	if err := keys.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if len(keys.PublicKeys) > maxServerKeys || len(keys.DHGroups) > maxServerKeys || len(keys.ECDHGroups) > maxServerKeys {
+		return _errors.ErrInputTooLarge
+	}
+	*v = keys
+	return nil
+}
+
+// Verify checks the signature, expiry and environment of the bundle
+func (v *SignedServerKeys) Verify(now int64) error {
+	rootKey, err := base64.StdEncoding.DecodeString(rootPublicKey)
+	if err != nil || len(rootKey) != ed25519.PublicKeySize {
+		return _errors.ErrNoRootKey
+	}
+	if !ed25519.Verify(rootKey, v.signedData(), v.Signature) {
+		return _errors.ErrInvalidSignature
+	}
+	if v.ExpiresAt <= now && devMode != "true" {
+		return _errors.ErrServerKeysExpired
+	}
+	if v.Env != buildEnv {
+		return _errors.ErrServerKeysEnv
+	}
+	return nil
+}
+
+// Sign signs the bundle by the root private key, it is used by the tools which publish the bundles
+func (v *SignedServerKeys) Sign(privateKey ed25519.PrivateKey) {
+	v.Signature = ed25519.Sign(privateKey, v.signedData())
+}
+
+// signedData returns prefix | env | 0x00 | expiresAt (little endian) | keys
+func (v *SignedServerKeys) signedData() []byte {
+	b := make([]byte, 0, len(serverKeysSignaturePrefix)+len(v.Env)+9+len(v.Keys))
+	b = append(b, serverKeysSignaturePrefix...)
+	b = append(b, v.Env...)
+	b = append(b, 0)
+	b = append(b, make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(b[len(b)-8:], uint64(v.ExpiresAt))
+	b = append(b, v.Keys...)
+	return b
+}
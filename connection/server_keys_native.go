@@ -0,0 +1,11 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river_conn
+
+// SetRootPublicKey sets the root key and the environment which the server keys are verified with. They are
+// compiled into the wasm binary, but the native tools accept them at runtime.
+func SetRootPublicKey(rootKey, env string) {
+	rootPublicKey = rootKey
+	buildEnv = env
+}
@@ -0,0 +1,12 @@
+package river_conn
+
+// Storage persists data by its key, empty data removes the key
+type Storage func(data, key string)
+
+// storage is where the connection info and the handshakes are saved, it is jsSave in the browser
+var storage Storage = defaultStorage
+
+// SetStorage replaces the storage, the native clients save the connection info in their own files
+func SetStorage(s Storage) {
+	storage = s
+}
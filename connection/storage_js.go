@@ -0,0 +1,12 @@
+//go:build js && wasm
+// +build js,wasm
+
+package river_conn
+
+import (
+	"syscall/js"
+)
+
+func defaultStorage(data, key string) {
+	js.Global().Call("jsSave", data, key)
+}
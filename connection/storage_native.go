@@ -0,0 +1,7 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river_conn
+
+// defaultStorage discards the data, the native clients must set their storage by SetStorage
+func defaultStorage(data, key string) {}
@@ -12,4 +12,50 @@ var (
 	ErrAuthFailed          = errors.New("creating auth key failed")
 	ErrNoAuthKey        = errors.New("no auth key")
 	ErrNotFound            = errors.New("not found")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrNoTempKey           = errors.New("no temporary auth key")
+	ErrTempKeyExpired      = errors.New("temporary auth key is expired")
+	ErrUnsupportedCurve    = errors.New("unsupported elliptic curve")
+	ErrInvalidDHPrime      = errors.New("dh prime is not a safe prime")
+	ErrDHPrimeTooSmall     = errors.New("dh prime is too small")
+	ErrInvalidDHGenerator  = errors.New("dh generator is not valid")
+	ErrInvalidDHPublicKey  = errors.New("dh public key is out of range")
+	ErrInvalidAuthKey      = errors.New("auth key has invalid size")
+	ErrValueTooLarge       = errors.New("value does not fit in the given size")
+	ErrUnsupportedPadding  = errors.New("unsupported rsa padding")
+	ErrUnsignedServerKeys  = errors.New("server keys are not signed")
+	ErrNoRootKey           = errors.New("root key is not set")
+	ErrInvalidSignature    = errors.New("invalid signature")
+	ErrServerKeysExpired   = errors.New("server keys are expired")
+	ErrServerKeysEnv       = errors.New("server keys belong to another environment")
+	ErrInvalidPrivateKey   = errors.New("invalid private key")
+	ErrHandshakeStep       = errors.New("handshake step is not expected")
+	ErrHandshakeExpired    = errors.New("handshake is expired")
+	ErrNonceMismatch       = errors.New("handshake nonce does not match")
+	ErrInvalidPQ           = errors.New("pq is not a composite number")
+	ErrSplitPQBudget       = errors.New("pq factorization exceeded its budget")
+	ErrPQTooLarge          = errors.New("pq is wider than 64 bits")
+	ErrConnInfoVersion     = errors.New("unknown connection info version")
+	ErrUnsupportedPasswordAlgorithm = errors.New("unsupported password algorithm")
+	ErrInvalidPasswordAlgorithm     = errors.New("invalid password algorithm parameters")
+	ErrInvalidSrpPrime              = errors.New("srp prime is not a 2048 bits safe prime")
+	ErrInvalidSrpGenerator          = errors.New("srp generator does not generate the prime order subgroup")
+	ErrInvalidSrpB                  = errors.New("srp B is out of range")
+	ErrInvalidSrpU                  = errors.New("srp u is zero")
+	ErrInvalidSrpA                  = errors.New("srp A is out of range")
+	ErrInvalidSrpVerifier           = errors.New("srp verifier is out of range")
+	ErrInvalidSrpM1                 = errors.New("srp M1 does not match")
+	ErrInvalidSrpM2                 = errors.New("srp M2 does not match")
+	ErrUnknownSrpID                 = errors.New("srp id is unknown or already verified")
+	ErrNoRecoveryAnswer             = errors.New("recovery question is not answered")
+	ErrNoCapture                    = errors.New("capture is not started")
+	ErrInvalidCapture               = errors.New("capture file is not valid")
+	ErrUnknownCaptureMode           = errors.New("unknown capture mode")
+	ErrCapturePassphrase            = errors.New("capture passphrase is empty or wrong")
+	ErrFrameTooLarge                = errors.New("frame is too large")
+	ErrInputTooLarge                = errors.New("input is too large")
+	ErrContainerTooDeep             = errors.New("containers are nested too deeply")
+	ErrMalformedInput               = errors.New("malformed input")
+	ErrInvalidJSON                  = errors.New("invalid JSON")
+	ErrAuthIDMismatch               = errors.New("auth id does not match the auth key")
 )
@@ -1,3 +1,19 @@
 #!/usr/bin/env bash
+set -e
 
-GOOS=js GOARCH=wasm GODEBUG=gcstoptheworld=1 GOGC=20 go build -ldflags="-s -w" -o /Users/hamidrezakk/ronak/river/web-app/public/bin/river.wasm .
\ No newline at end of file
+# a wasm without the root key rejects every ServerKeys bundle, only the dev builds accept them unsigned
+if [ -z "${RIVER_ROOT_KEY}" ] && [ "${RIVER_DEV}" != "true" ]; then
+	echo "RIVER_ROOT_KEY is not set, set it or RIVER_DEV=true for a dev build" >&2
+	exit 1
+fi
+
+PKG=git.ronaksoft.com/river/web-wasm/connection
+# -s is left out, wasmsize reports the size of each package by the name section and strips it
+LDFLAGS="-w -X ${PKG}.rootPublicKey=${RIVER_ROOT_KEY} -X ${PKG}.buildEnv=${RIVER_ENV:-prod} -X ${PKG}.devMode=${RIVER_DEV:-false}"
+OUT=${RIVER_WASM_OUT:-/Users/hamidrezakk/ronak/river/web-app/public/bin/river.wasm}
+MAX_SIZE=${RIVER_MAX_WASM_SIZE:-6291456}
+
+# the stripped binary of the last build is up to date for go build, which would not write the names again
+rm -f "${OUT}"
+GOOS=js GOARCH=wasm GODEBUG=gcstoptheworld=1 GOGC=20 go build -ldflags="${LDFLAGS}" -o "${OUT}" .
+go run ./cmd/wasmsize -budget "${MAX_SIZE}" -strip -pkg . "${OUT}"
@@ -5,7 +5,7 @@ go 1.15
 require (
 	github.com/gogo/protobuf v1.3.1
 	github.com/golang/protobuf v1.4.1 // indirect
-	github.com/mailru/easyjson v0.7.6
-	github.com/monnand/dhkx v0.0.0-20180522003156-9e5b033f1ac4
+	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897
+	golang.org/x/net v0.0.0-20200625001655-4c5254603344
 	google.golang.org/protobuf v1.25.0 // indirect
 )
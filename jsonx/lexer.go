@@ -0,0 +1,389 @@
+package jsonx
+
+import (
+	"encoding/base64"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// MaxDepth is the number of the levels of the nested objects and arrays which are read
+const MaxDepth = 64
+
+// Lexer
+// Reads the values in the order they are expected, the first error is kept and the values which are read
+// after it are zero. The null fields are skipped, so they keep their zero values.
+type Lexer struct {
+	data  []byte
+	pos   int
+	depth int
+	err   error
+}
+
+// NewLexer returns the lexer of data
+func NewLexer(data []byte) *Lexer {
+	return &Lexer{data: data}
+}
+
+// Error returns the first error
+func (l *Lexer) Error() error {
+	return l.err
+}
+
+// AddError keeps err if there was no error
+func (l *Lexer) AddError(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// Done returns the first error, the value must be followed by nothing but whitespace
+func (l *Lexer) Done() error {
+	if l.skipSpace(); l.pos < len(l.data) {
+		l.AddError(_errors.ErrInvalidJSON)
+	}
+	return l.err
+}
+
+// IsDelim returns true if the next token is c
+func (l *Lexer) IsDelim(c byte) bool {
+	return l.peek() == c
+}
+
+// Null consumes the next token if it is null
+func (l *Lexer) Null() bool {
+	if l.peek() != 'n' {
+		return false
+	}
+	return l.literal("null")
+}
+
+// Object reads an object and calls field by the name of each field whose value is not null, field must
+// read the value or Skip it. Null is read as an empty object.
+func (l *Lexer) Object(field func(name string)) {
+	if l.Null() || !l.enter('{') {
+		return
+	}
+	defer l.leave()
+	if l.IsDelim('}') {
+		l.pos++
+		return
+	}
+	for l.err == nil {
+		name := l.String()
+		if !l.delim(':') {
+			return
+		}
+		if !l.Null() {
+			field(name)
+		}
+		if l.IsDelim(',') {
+			l.pos++
+			continue
+		}
+		l.delim('}')
+		return
+	}
+}
+
+// Array reads an array and calls elem to read each element. Null is read as an empty array.
+func (l *Lexer) Array(elem func()) {
+	if l.Null() || !l.enter('[') {
+		return
+	}
+	defer l.leave()
+	if l.IsDelim(']') {
+		l.pos++
+		return
+	}
+	for l.err == nil {
+		elem()
+		if l.IsDelim(',') {
+			l.pos++
+			continue
+		}
+		l.delim(']')
+		return
+	}
+}
+
+// Skip reads any value and drops it
+func (l *Lexer) Skip() {
+	switch l.peek() {
+	case '{':
+		l.Object(func(string) { l.Skip() })
+	case '[':
+		l.Array(l.Skip)
+	case '"':
+		_ = l.String()
+	case 't':
+		l.literal("true")
+	case 'f':
+		l.literal("false")
+	case 'n':
+		l.literal("null")
+	default:
+		l.number()
+	}
+}
+
+// String reads a string
+func (l *Lexer) String() string {
+	if l.peek() != '"' {
+		l.AddError(_errors.ErrInvalidJSON)
+		return ""
+	}
+	start := l.pos + 1
+	for i := start; i < len(l.data); i++ {
+		switch l.data[i] {
+		case '"':
+			l.pos = i + 1
+			return string(l.data[start:i])
+		case '\\':
+			return l.unescape(start)
+		}
+	}
+	l.AddError(_errors.ErrInvalidJSON)
+	return ""
+}
+
+// Bytes reads a base64 string
+func (l *Lexer) Bytes() []byte {
+	s := l.String()
+	if l.err != nil {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		l.AddError(_errors.ErrInvalidJSON)
+		return nil
+	}
+	return b
+}
+
+// Int64 reads an integer
+func (l *Lexer) Int64() int64 {
+	return l.int(64)
+}
+
+// Int32 reads an integer which fits in int32
+func (l *Lexer) Int32() int32 {
+	return int32(l.int(32))
+}
+
+// Int reads an integer
+func (l *Lexer) Int() int {
+	return int(l.int(64))
+}
+
+// Uint32 reads an unsigned integer which fits in uint32
+func (l *Lexer) Uint32() uint32 {
+	return uint32(l.uint(32))
+}
+
+// Uint8 reads an unsigned integer which fits in uint8
+func (l *Lexer) Uint8() uint8 {
+	return uint8(l.uint(8))
+}
+
+// Float64 reads a number
+func (l *Lexer) Float64() float64 {
+	s := l.number()
+	if l.err != nil {
+		return 0
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		l.AddError(_errors.ErrInvalidJSON)
+	}
+	return n
+}
+
+func (l *Lexer) int(bitSize int) int64 {
+	s := l.number()
+	if l.err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		l.AddError(_errors.ErrInvalidJSON)
+	}
+	return n
+}
+
+func (l *Lexer) uint(bitSize int) uint64 {
+	s := l.number()
+	if l.err != nil {
+		return 0
+	}
+	n, err := strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		l.AddError(_errors.ErrInvalidJSON)
+	}
+	return n
+}
+
+// number reads the characters of a number, the same characters as easyjson accepted, their value is checked
+// by the parser of its type
+func (l *Lexer) number() string {
+	if c := l.peek(); c != '-' && (c < '0' || c > '9') {
+		l.AddError(_errors.ErrInvalidJSON)
+		return ""
+	}
+	start := l.pos
+	hasE, afterE, hasDot := false, false, false
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch {
+		case c >= '0' && c <= '9':
+			afterE = false
+		case c == '.' && !hasDot:
+			hasDot = true
+		case (c == 'e' || c == 'E') && !hasE:
+			hasE, afterE, hasDot = true, true, true
+		case (c == '+' || c == '-') && afterE:
+			afterE = false
+		default:
+			return string(l.data[start:l.pos])
+		}
+	}
+	return string(l.data[start:l.pos])
+}
+
+// unescape reads the string which starts at start and has escapes, a broken surrogate pair is read as U+FFFD
+func (l *Lexer) unescape(start int) string {
+	buf := make([]byte, 0, len(l.data)-start)
+	for i := start; i < len(l.data); {
+		c := l.data[i]
+		if c == '"' {
+			l.pos = i + 1
+			return string(buf)
+		}
+		if c != '\\' {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+		if i+1 >= len(l.data) {
+			break
+		}
+		switch l.data[i+1] {
+		case '"', '\\', '/':
+			buf = append(buf, l.data[i+1])
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r := hex4(l.data[i+2:])
+			if r < 0 {
+				l.AddError(_errors.ErrInvalidJSON)
+				return ""
+			}
+			if utf16.IsSurrogate(r) {
+				if i+12 <= len(l.data) && l.data[i+6] == '\\' && l.data[i+7] == 'u' {
+					if r2 := utf16.DecodeRune(r, hex4(l.data[i+8:])); r2 != utf8.RuneError {
+						buf = append(buf, string(r2)...)
+						i += 12
+						continue
+					}
+				}
+				r = utf8.RuneError
+			}
+			buf = append(buf, string(r)...)
+			i += 6
+			continue
+		default:
+			l.AddError(_errors.ErrInvalidJSON)
+			return ""
+		}
+		i += 2
+	}
+	l.AddError(_errors.ErrInvalidJSON)
+	return ""
+}
+
+// hex4 returns the value of the 4 hex digits at the start of b, or -1
+func hex4(b []byte) rune {
+	if len(b) < 4 {
+		return -1
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return -1
+		}
+		r = r<<4 | rune(c)
+	}
+	return r
+}
+
+func (l *Lexer) literal(s string) bool {
+	l.skipSpace()
+	if l.err != nil || len(l.data)-l.pos < len(s) || string(l.data[l.pos:l.pos+len(s)]) != s {
+		l.AddError(_errors.ErrInvalidJSON)
+		return false
+	}
+	l.pos += len(s)
+	return true
+}
+
+// enter opens an object or an array, nesting deeper than MaxDepth is an error
+func (l *Lexer) enter(c byte) bool {
+	if !l.delim(c) {
+		return false
+	}
+	if l.depth == MaxDepth {
+		l.AddError(_errors.ErrInvalidJSON)
+		return false
+	}
+	l.depth++
+	return true
+}
+
+func (l *Lexer) leave() {
+	l.depth--
+}
+
+func (l *Lexer) delim(c byte) bool {
+	if l.peek() != c {
+		l.AddError(_errors.ErrInvalidJSON)
+		return false
+	}
+	l.pos++
+	return true
+}
+
+// peek returns the next character after whitespace, or zero at the end or after an error
+func (l *Lexer) peek() byte {
+	if l.skipSpace(); l.err != nil || l.pos >= len(l.data) {
+		return 0
+	}
+	return l.data[l.pos]
+}
+
+func (l *Lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case ' ', '\t', '\r', '\n':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package jsonx
+
+import (
+	"encoding/base64"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hexChars = "0123456789abcdef"
+
+// Writer
+// Appends the JSON of the values in the order they are written, the commas are added by the writer. The
+// strings are escaped the same way easyjson did, so the persisted JSON does not change.
+type Writer struct {
+	buf   []byte
+	comma bool
+}
+
+// Bytes returns the written JSON
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+// ObjectStart opens an object, its fields are written by Field and their values
+func (w *Writer) ObjectStart() {
+	w.sep()
+	w.buf = append(w.buf, '{')
+	w.comma = false
+}
+
+// ObjectEnd closes the object
+func (w *Writer) ObjectEnd() {
+	w.buf = append(w.buf, '}')
+	w.comma = true
+}
+
+// Array writes the array of n elements which are written by elem, a nil slice is written as null
+func (w *Writer) Array(null bool, n int, elem func(i int)) {
+	if null {
+		w.Null()
+		return
+	}
+	w.sep()
+	w.buf = append(w.buf, '[')
+	w.comma = false
+	for i := 0; i < n; i++ {
+		elem(i)
+	}
+	w.buf = append(w.buf, ']')
+	w.comma = true
+}
+
+// Field writes the name of the field, its value must be written next
+func (w *Writer) Field(name string) {
+	w.sep()
+	w.str(name)
+	w.buf = append(w.buf, ':')
+	w.comma = false
+}
+
+// Null writes null
+func (w *Writer) Null() {
+	w.sep()
+	w.buf = append(w.buf, "null"...)
+	w.comma = true
+}
+
+// String writes the escaped string
+func (w *Writer) String(s string) {
+	w.sep()
+	w.str(s)
+	w.comma = true
+}
+
+// Int64 writes the integer
+func (w *Writer) Int64(n int64) {
+	w.sep()
+	w.buf = strconv.AppendInt(w.buf, n, 10)
+	w.comma = true
+}
+
+// Uint64 writes the unsigned integer
+func (w *Writer) Uint64(n uint64) {
+	w.sep()
+	w.buf = strconv.AppendUint(w.buf, n, 10)
+	w.comma = true
+}
+
+// Float64 writes the shortest representation of the number
+func (w *Writer) Float64(n float64) {
+	w.sep()
+	w.buf = strconv.AppendFloat(w.buf, n, 'g', -1, 64)
+	w.comma = true
+}
+
+// Base64 writes the bytes as a base64 string, nil is written as null
+func (w *Writer) Base64(b []byte) {
+	if b == nil {
+		w.Null()
+		return
+	}
+	w.sep()
+	w.buf = append(w.buf, '"')
+	w.buf = append(w.buf, base64.StdEncoding.EncodeToString(b)...)
+	w.buf = append(w.buf, '"')
+	w.comma = true
+}
+
+func (w *Writer) sep() {
+	if w.comma {
+		w.buf = append(w.buf, ',')
+	}
+}
+
+// str quotes s, the HTML characters and the line separators are escaped too and the broken UTF-8 is
+// replaced by U+FFFD
+func (w *Writer) str(s string) {
+	w.buf = append(w.buf, '"')
+	p := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			w.buf = append(w.buf, s[p:i]...)
+			switch c {
+			case '\t':
+				w.buf = append(w.buf, `\t`...)
+			case '\r':
+				w.buf = append(w.buf, `\r`...)
+			case '\n':
+				w.buf = append(w.buf, `\n`...)
+			case '\\':
+				w.buf = append(w.buf, `\\`...)
+			case '"':
+				w.buf = append(w.buf, `\"`...)
+			default:
+				w.buf = append(w.buf, `\u00`...)
+				w.buf = append(w.buf, hexChars[c>>4], hexChars[c&0xf])
+			}
+			i++
+			p = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			w.buf = append(w.buf, s[p:i]...)
+			w.buf = append(w.buf, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			w.buf = append(w.buf, s[p:i]...)
+			w.buf = append(w.buf, `\u202`...)
+			w.buf = append(w.buf, hexChars[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		p = i
+	}
+	w.buf = append(w.buf, s[p:]...)
+	w.buf = append(w.buf, '"')
+}
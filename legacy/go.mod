@@ -0,0 +1,8 @@
+module git.ronaksoft.com/river/web-wasm/legacy
+
+go 1.15
+
+require (
+	github.com/mailru/easyjson v0.7.6
+	github.com/monnand/dhkx v0.0.0-20180522003156-9e5b033f1ac4
+)
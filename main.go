@@ -1,33 +1,60 @@
+//go:build js && wasm
+// +build js,wasm
+
 package main
 
 import (
 	"encoding/base64"
 	"fmt"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
 	"git.ronaksoft.com/river/web-wasm/msg"
 	"git.ronaksoft.com/river/web-wasm/river"
 	"math/rand"
+	"strconv"
 	"syscall/js"
 	"time"
 )
 
 var (
-	_river *river.River
+	_accounts *river.Registry
+	// _selfTest is the result of the fast self test at startup, no account is loaded if it failed
+	_selfTest error
 )
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
-	_river = new(river.River)
+	_accounts = river.NewRegistry()
+	_selfTest = river.SelfTest(false)
+	if _selfTest != nil {
+		fmt.Println("Error", _selfTest.Error())
+	}
 
 	done := make(chan struct{}, 0)
 
 	global := js.Global()
 	global.Set("wasmLoad", js.FuncOf(load))
+	global.Set("wasmRemove", js.FuncOf(remove))
 	global.Set("wasmSetServerTime", js.FuncOf(setServerTime))
+	global.Set("wasmSetServerSalt", js.FuncOf(setServerSalt))
+	global.Set("wasmSetDHPoolSize", js.FuncOf(setDHPoolSize))
 	global.Set("wasmAuth", js.FuncOf(auth))
+	global.Set("wasmResumeAuth", js.FuncOf(resumeAuth))
+	global.Set("wasmCancelAuth", js.FuncOf(cancelAuth))
+	global.Set("wasmBindTempKey", js.FuncOf(bindTempKey))
+	global.Set("wasmTempKeyBound", js.FuncOf(tempKeyBound))
+	global.Set("wasmDropTempKeys", js.FuncOf(dropTempKeys))
+	global.Set("wasmExportKey", js.FuncOf(exportKey))
+	global.Set("wasmImportKey", js.FuncOf(importKey))
 	global.Set("wasmDecode", js.FuncOf(decode))
 	global.Set("wasmEncode", js.FuncOf(encode))
 	global.Set("wasmGenSrpHash", js.FuncOf(generateSrpHash))
 	global.Set("wasmGenInputPassword", js.FuncOf(generateInputPassword))
+	global.Set("wasmVerifySrpM2", js.FuncOf(verifySrpM2))
+	global.Set("wasmGenPasswordSettings", js.FuncOf(generatePasswordSettings))
+	global.Set("wasmPasswordStrength", js.FuncOf(passwordStrength))
+	global.Set("wasmStartCapture", js.FuncOf(startCapture))
+	global.Set("wasmStopCapture", js.FuncOf(stopCapture))
+	global.Set("wasmSelfTest", js.FuncOf(selfTest))
 
 	js.Global().Call("jsLoaded", nil)
 	<-done
@@ -36,9 +63,19 @@ func main() {
 }
 
 func load(this js.Value, args []js.Value) interface{} {
-	connInfo := args[0].String()
-	serverPubKeys := args[1].String()
-	err := _river.Load(connInfo, serverPubKeys)
+	if _selfTest != nil {
+		return _selfTest.Error()
+	}
+	handle := args[0].String()
+	connInfo := args[1].String()
+	serverPubKeys := args[2].String()
+	r := _accounts.GetOrCreate(handle)
+	err := r.Load(connInfo, serverPubKeys)
+	// the key pairs are needed the most when there is no auth key yet, they are generated only if
+	// the server keys are loaded
+	if err == nil || err == _errors.ErrNoAuthKey {
+		r.WarmDHPool()
+	}
 	if err != nil {
 		return err.Error()
 	}
@@ -46,73 +83,231 @@ func load(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+func remove(this js.Value, args []js.Value) interface{} {
+	_accounts.Remove(args[0].String())
+	return nil
+}
+
 func setServerTime(this js.Value, args []js.Value) interface{} {
-	serverTime := args[0].Int()
-	_river.ConnInfo.SetServerTime(int64(serverTime))
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	serverTime := args[1].Int()
+	r.SetServerTime(int64(serverTime))
+	return nil
+}
+
+func setServerSalt(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	serverSalt := args[1].Int()
+	r.SetServerSalt(int64(serverSalt))
+	return nil
+}
+
+func setDHPoolSize(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	r.SetDHPoolSize(args[1].Int())
 	return nil
 }
 
 func auth(this js.Value, args []js.Value) interface{} {
 	go func(inps []js.Value) {
-		id := inps[0].Int()
-		step := inps[1].Int()
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		id := int64(inps[1].Int())
+		step := inps[2].Int()
 		var (
 			bytes []byte
-			err   error
 			enc   []byte
 		)
+		progress := func(p int64) {
+			dispatchProgress(r.Handle(), p)
+		}
 		switch step {
 		case 1:
-			bytes = _river.AuthStep1(dispatchProgress)
+			var (
+				clusterID int32
+				tempTTL   int
+			)
+			if len(inps) > 4 {
+				clusterID = int32(inps[4].Int())
+			}
+			if len(inps) > 5 {
+				tempTTL = inps[5].Int()
+			}
+			if tempTTL > 0 {
+				bytes = r.TempAuthStep1(id, int32(tempTTL), progress)
+			} else {
+				bytes = r.AuthStep1(id, clusterID, progress)
+			}
 		case 2:
-			enc, err = base64.StdEncoding.DecodeString(args[2].String())
+			enc, err = base64.StdEncoding.DecodeString(inps[3].String())
 			if err != nil {
 				return
 			}
 
-			bytes, err = _river.AuthStep2(enc, dispatchProgress)
+			bytes, err = r.AuthStep2(id, enc, progress)
 			if err != nil {
 				return
 			}
 		case 3:
-			enc, err = base64.StdEncoding.DecodeString(args[2].String())
+			enc, err = base64.StdEncoding.DecodeString(inps[3].String())
 			if err != nil {
 				return
 			}
 
-			bytes, err = _river.AuthStep3(enc, dispatchProgress)
+			bytes, err = r.AuthStep3(id, enc, progress)
 			if err != nil {
 				return
 			}
 		}
-		js.Global().Call("jsAuth", id, step, base64.StdEncoding.EncodeToString(bytes))
+		js.Global().Call("jsAuth", r.Handle(), id, step, base64.StdEncoding.EncodeToString(bytes))
+	}(args)
+	return nil
+}
+
+// resumeAuth restores the handshake which is saved after step 2 and dispatches its request again,
+// the response must be passed to step 3
+func resumeAuth(this js.Value, args []js.Value) interface{} {
+	go func(inps []js.Value) {
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		id := int64(inps[1].Int())
+		bytes, err := r.ResumeHandshake(id, []byte(inps[2].String()))
+		if err != nil {
+			return
+		}
+
+		js.Global().Call("jsAuth", r.Handle(), id, 2, base64.StdEncoding.EncodeToString(bytes))
+	}(args)
+	return nil
+}
+
+// cancelAuth stops the running step of the handshake, e.g. a long PQ factorization, and forgets it
+func cancelAuth(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	r.CancelHandshake(int64(args[1].Int()))
+	return nil
+}
+
+func bindTempKey(this js.Value, args []js.Value) interface{} {
+	go func(inps []js.Value) {
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		reqID := uint64(inps[1].Int())
+		bytes, err := r.BindTempKey(reqID)
+		if err != nil {
+			return
+		}
+
+		js.Global().Call("jsEncode", r.Handle(), true, reqID, base64.StdEncoding.EncodeToString(bytes))
 	}(args)
 	return nil
 }
 
+func tempKeyBound(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	err = r.TempKeyBound()
+	if err != nil {
+		return err.Error()
+	}
+
+	// Ask the app to negotiate the next temporary key before this one expires
+	handle := r.Handle()
+	r.ScheduleTempKeyRotation(func() {
+		js.Global().Call("jsRotateTempKey", handle)
+	})
+	return nil
+}
+
+// dropTempKeys goes back to the permanent key, wasmEncode fails once the bound temporary key is expired
+// until it is rotated or dropped
+func dropTempKeys(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	r.DropTempKeys()
+	return nil
+}
+
+func exportKey(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return nil
+	}
+	bytes, err := r.ExportKey(int32(args[1].Int()))
+	if err != nil {
+		return nil
+	}
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+func importKey(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	data, err := base64.StdEncoding.DecodeString(args[2].String())
+	if err != nil {
+		return err.Error()
+	}
+	err = r.ImportKey(int32(args[1].Int()), data)
+	if err != nil {
+		return err.Error()
+	}
+	return nil
+}
+
 func decode(this js.Value, args []js.Value) interface{} {
 	go func(inps []js.Value) {
-		withParse := inps[0].Bool()
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		withParse := inps[1].Bool()
 
-		enc, err := base64.StdEncoding.DecodeString(inps[1].String())
+		enc, err := base64.StdEncoding.DecodeString(inps[2].String())
 		if err != nil {
 			return
 		}
 
-		env, err := _river.Decode(enc)
+		env, err := r.Decode(enc)
 		if err != nil || env == nil {
 			return
 		}
+		if r.NeedsTimeSync() {
+			js.Global().Call("jsTimeSync", r.Handle())
+		}
 
-		reqId := inps[2].Int()
+		reqId := inps[3].Int()
 
 		if withParse {
-			parseEnvelope(env)
+			parseEnvelope(r, env)
 		} else {
 			if reqId != 0 {
 				env.RequestID = uint64(reqId)
 			}
-			js.Global().Call("jsDecode", false, env.RequestID, env.Constructor, base64.StdEncoding.EncodeToString(env.Message))
+			js.Global().Call("jsDecode", r.Handle(), false, env.RequestID, env.Constructor, base64.StdEncoding.EncodeToString(env.Message))
 		}
 	}(args)
 
@@ -121,32 +316,46 @@ func decode(this js.Value, args []js.Value) interface{} {
 
 func encode(this js.Value, args []js.Value) interface{} {
 	go func(inps []js.Value) {
-		withSend := inps[0].Bool()
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		withSend := inps[1].Bool()
 
 		env := new(msg.MessageEnvelope)
 
-		env.RequestID = uint64(args[1].Int())
-		env.Constructor = int64(args[2].Int())
-		enc, err := base64.StdEncoding.DecodeString(args[3].String())
+		env.RequestID = uint64(inps[2].Int())
+		env.Constructor = int64(inps[3].Int())
+		enc, err := base64.StdEncoding.DecodeString(inps[4].String())
 		if err != nil {
 			return
 		}
 		env.Message = enc
 
-		if len(args) > 4 {
-			teamId := args[4].String()
-			teamAccessHash := args[5].String()
+		if len(inps) > 5 {
+			teamId := inps[5].String()
+			teamAccessHash := inps[6].String()
 			if teamId != "0" && teamAccessHash != "0" {
 				env.Header = river.TeamHeader(teamId, teamAccessHash)
 			}
 		}
 
-		bytes, err := _river.Encode(env)
+		var clusterID int32
+		if len(inps) > 7 {
+			clusterID = int32(inps[7].Int())
+		}
+
+		bytes, err := r.EncodeFor(clusterID, env)
+		if err == _errors.ErrTempKeyExpired {
+			// the rotation was missed, the app negotiates the next key or drops them by wasmDropTempKeys
+			js.Global().Call("jsRotateTempKey", r.Handle())
+			return
+		}
 		if err != nil {
 			return
 		}
 
-		js.Global().Call("jsEncode", withSend, env.RequestID, base64.StdEncoding.EncodeToString(bytes))
+		js.Global().Call("jsEncode", r.Handle(), withSend, env.RequestID, base64.StdEncoding.EncodeToString(bytes))
 	}(args)
 
 	return nil
@@ -154,78 +363,217 @@ func encode(this js.Value, args []js.Value) interface{} {
 
 func generateSrpHash(this js.Value, args []js.Value) interface{} {
 	go func(inps []js.Value) {
-		id := inps[0].Int()
-		pass, err := base64.StdEncoding.DecodeString(inps[1].String())
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		id := inps[1].Int()
+		pass, err := base64.StdEncoding.DecodeString(inps[2].String())
 		if err != nil {
 			return
 		}
 
-		algorithm := inps[2].Int()
-		algorithmData, err := base64.StdEncoding.DecodeString(inps[3].String())
+		algorithm := inps[3].Int()
+		algorithmData, err := base64.StdEncoding.DecodeString(inps[4].String())
 		if err != nil {
 			return
 		}
 
-		res, err := _river.GenSrpHash(pass, int64(algorithm), algorithmData)
+		res, err := r.GenSrpHash(pass, int64(algorithm), algorithmData)
 		if err != nil {
 			return
 		}
 
-		js.Global().Call("jsGenSrpHash", id, base64.StdEncoding.EncodeToString(res))
+		js.Global().Call("jsGenSrpHash", r.Handle(), id, base64.StdEncoding.EncodeToString(res))
 	}(args)
 	return nil
 }
 
 func generateInputPassword(this js.Value, inps []js.Value) interface{} {
 	go func(inps []js.Value) {
-		id := inps[0].Int()
-		pass, err := base64.StdEncoding.DecodeString(inps[1].String())
+		r, err := _accounts.Get(inps[0].String())
+		if err != nil {
+			return
+		}
+		id := inps[1].Int()
+		pass, err := base64.StdEncoding.DecodeString(inps[2].String())
 		if err != nil {
 			return
 		}
 
-		accountPass, err := base64.StdEncoding.DecodeString(inps[2].String())
+		accountPass, err := base64.StdEncoding.DecodeString(inps[3].String())
 		if err != nil {
 			return
 		}
 
-		res, err := _river.GenInputPassword(pass, accountPass)
+		res, err := r.GenInputPassword(pass, accountPass)
 		if err != nil {
 			return
 		}
 
-		js.Global().Call("jsGenInputPassword", id, base64.StdEncoding.EncodeToString(res))
+		js.Global().Call("jsGenInputPassword", r.Handle(), id, base64.StdEncoding.EncodeToString(res))
 	}(inps)
 	return nil
 }
 
-func dispatchProgress(progress int64) {
-	js.Global().Call("jsAuthProgress", progress)
+// verifySrpM2 accepts the SrpID and M2 of the server base64 encoded, it returns the error if the server
+// could not prove it knows the password verifier
+func verifySrpM2(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	srpID, err := strconv.ParseInt(args[1].String(), 10, 64)
+	if err != nil {
+		return err.Error()
+	}
+	m2, err := base64.StdEncoding.DecodeString(args[2].String())
+	if err != nil {
+		return err.Error()
+	}
+	err = r.VerifySrpM2(srpID, m2)
+	if err != nil {
+		return err.Error()
+	}
+	return nil
 }
 
-func parseEnvelope(m *msg.MessageEnvelope) {
-	switch m.Constructor {
-	case msg.C_MessageContainer:
-		x := new(msg.MessageContainer)
-		err := x.Unmarshal(m.Message)
+// generatePasswordSettings accepts the old and the new passwords, AccountPassword, the hint and an object
+// of the recovery answers keyed by the question ids
+func generatePasswordSettings(this js.Value, args []js.Value) interface{} {
+	go func(inps []js.Value) {
+		r, err := _accounts.Get(inps[0].String())
 		if err != nil {
-			fmt.Println("Error", err.Error())
 			return
 		}
+		id := inps[1].Int()
+		oldPass, err := base64.StdEncoding.DecodeString(inps[2].String())
+		if err != nil {
+			return
+		}
+		newPass, err := base64.StdEncoding.DecodeString(inps[3].String())
+		if err != nil {
+			return
+		}
+		accountPass, err := base64.StdEncoding.DecodeString(inps[4].String())
+		if err != nil {
+			return
+		}
+		hint := inps[5].String()
+
+		answers := make(map[int32]string)
+		if len(inps) > 6 && inps[6].Type() == js.TypeObject {
+			keys := js.Global().Get("Object").Call("keys", inps[6])
+			for i := 0; i < keys.Length(); i++ {
+				key := keys.Index(i).String()
+				questionID, err := strconv.ParseInt(key, 10, 32)
+				if err != nil {
+					continue
+				}
+				answers[int32(questionID)] = inps[6].Get(key).String()
+			}
+		}
 
-		for _, envelope := range x.Envelopes {
-			parseEnvelope(envelope)
+		res, err := r.GenPasswordSettings(oldPass, newPass, accountPass, hint, answers)
+		if err != nil {
+			return
 		}
-	case msg.C_UpdateContainer:
-		x := new(msg.UpdateContainer)
-		err := x.Unmarshal(m.Message)
+
+		js.Global().Call("jsGenPasswordSettings", r.Handle(), id, base64.StdEncoding.EncodeToString(res))
+	}(args)
+	return nil
+}
+
+// passwordStrength accepts the password base64 encoded and returns the estimation as JSON, it is
+// synchronous since the app checks the password while it is being typed
+func passwordStrength(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return nil
+	}
+	password, err := base64.StdEncoding.DecodeString(args[1].String())
+	if err != nil {
+		return nil
+	}
+	res := r.PasswordStrength(string(password))
+	bytes, err := res.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	return string(bytes)
+}
+
+// selfTest returns the error of the fast self test which is run at startup. If full is true, the full
+// self test is run too and its error, or null, is passed to jsSelfTest.
+func selfTest(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 && args[0].Bool() {
+		go func() {
+			var res interface{}
+			if err := river.SelfTest(true); err != nil {
+				res = err.Error()
+			}
+			js.Global().Call("jsSelfTest", res)
+		}()
+	}
+	if _selfTest != nil {
+		return _selfTest.Error()
+	}
+	return nil
+}
+
+func startCapture(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	r.StartCapture(args[1].Int())
+	return nil
+}
+
+// stopCapture accepts the mode of the capture file and the base64 passphrase of the encrypted captures,
+// the capture file is passed to jsCapture
+func stopCapture(this js.Value, args []js.Value) interface{} {
+	r, err := _accounts.Get(args[0].String())
+	if err != nil {
+		return err.Error()
+	}
+	mode := args[1].String()
+	passphrase, err := base64.StdEncoding.DecodeString(args[2].String())
+	if err != nil {
+		return err.Error()
+	}
+	if err = river.CheckCaptureMode(mode, passphrase); err != nil {
+		return err.Error()
+	}
+	go func() {
+		data, err := r.StopCapture(mode, passphrase)
 		if err != nil {
-			fmt.Println("Error", err.Error())
 			return
 		}
+		js.Global().Call("jsCapture", r.Handle(), string(data))
+	}()
+	return nil
+}
+
+func dispatchProgress(handle string, progress int64) {
+	js.Global().Call("jsAuthProgress", handle, progress)
+}
+
+// jsDispatcher delivers the dispatched envelopes of the account to JS
+type jsDispatcher struct {
+	handle string
+}
+
+func (d jsDispatcher) OnUpdate(data []byte) {
+	js.Global().Call("jsUpdate", d.handle, base64.StdEncoding.EncodeToString(data))
+}
+
+func (d jsDispatcher) OnMessage(requestID uint64, constructor int64, data []byte) {
+	js.Global().Call("jsDecode", d.handle, true, requestID, constructor, base64.StdEncoding.EncodeToString(data))
+}
 
-		js.Global().Call("jsUpdate", base64.StdEncoding.EncodeToString(m.Message))
-	default:
-		js.Global().Call("jsDecode", true, m.RequestID, m.Constructor, base64.StdEncoding.EncodeToString(m.Message))
+func parseEnvelope(r *river.River, m *msg.MessageEnvelope) {
+	if err := r.Dispatch(m, jsDispatcher{handle: r.Handle()}); err != nil {
+		fmt.Println("Error", err.Error())
 	}
 }
@@ -4,13 +4,24 @@ package msg
 const C_MessageEnvelope int64 = 535232465
 const C_MessageContainer int64 = 1972016308
 const C_Error int64 = 2619118453
+const C_Redirect int64 = 981138557
 
 
 // River
 const C_SystemGetServerTime int64 = 1321179349
+const C_SystemServerTime int64 = 2854614486
 const C_SystemGetInfo int64 = 1486296237
 const C_SystemGetSalts int64 = 1705203315
 const C_InitConnect int64 = 4150793517
 const C_InitCompleteAuth int64 = 1583178320
+const C_InitResponse int64 = 4130340247
+const C_InitAuthCompleted int64 = 627708982
 const C_PasswordAlgorithmVer6A int64 = 341860043
-const C_UpdateContainer int64 = 661712615
\ No newline at end of file
+const C_PasswordAlgorithmVer6AArgon2id int64 = 1043673236
+const C_AccountPassword int64 = 4178767656
+const C_InputPassword int64 = 513021899
+const C_AccountUpdatePasswordSettings int64 = 3193945896
+const C_RecoveryAnswer int64 = 2390171437
+const C_UpdateContainer int64 = 661712615
+const C_AuthBindTempKey int64 = 897088811
+const C_AuthBindTempKeyInner int64 = 2070395335
\ No newline at end of file
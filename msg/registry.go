@@ -0,0 +1,73 @@
+package msg
+
+import (
+	"strconv"
+)
+
+// Message
+// All the generated messages are marshaled and unmarshaled by themselves
+type Message interface {
+	Marshal() ([]byte, error)
+	Unmarshal(dAtA []byte) error
+}
+
+// constructorInfo
+// The functions which have no message of their own, e.g. SystemGetServerTime, have no factory
+// and their envelopes have no body
+type constructorInfo struct {
+	name       string
+	newMessage func() Message
+}
+
+var constructors = map[int64]constructorInfo{
+	C_MessageEnvelope:                {"MessageEnvelope", func() Message { return &MessageEnvelope{} }},
+	C_MessageContainer:               {"MessageContainer", func() Message { return &MessageContainer{} }},
+	C_Error:                          {"Error", func() Message { return &Error{} }},
+	C_Redirect:                       {"Redirect", func() Message { return &Redirect{} }},
+	C_SystemGetServerTime:            {"SystemGetServerTime", nil},
+	C_SystemServerTime:               {"SystemServerTime", func() Message { return &SystemServerTime{} }},
+	C_SystemGetInfo:                  {"SystemGetInfo", nil},
+	C_SystemGetSalts:                 {"SystemGetSalts", nil},
+	C_InitConnect:                    {"InitConnect", func() Message { return &InitConnect{} }},
+	C_InitCompleteAuth:               {"InitCompleteAuth", func() Message { return &InitCompleteAuth{} }},
+	C_InitResponse:                   {"InitResponse", func() Message { return &InitResponse{} }},
+	C_InitAuthCompleted:              {"InitAuthCompleted", func() Message { return &InitAuthCompleted{} }},
+	C_PasswordAlgorithmVer6A:         {"PasswordAlgorithmVer6A", func() Message { return &PasswordAlgorithmVer6A{} }},
+	C_PasswordAlgorithmVer6AArgon2id: {"PasswordAlgorithmVer6AArgon2id", func() Message { return &PasswordAlgorithmVer6AArgon2Id{} }},
+	C_AccountPassword:                {"AccountPassword", func() Message { return &AccountPassword{} }},
+	C_InputPassword:                  {"InputPassword", func() Message { return &InputPassword{} }},
+	C_AccountUpdatePasswordSettings:  {"AccountUpdatePasswordSettings", func() Message { return &AccountUpdatePasswordSettings{} }},
+	C_RecoveryAnswer:                 {"RecoveryAnswer", func() Message { return &RecoveryAnswer{} }},
+	C_UpdateContainer:                {"UpdateContainer", func() Message { return &UpdateContainer{} }},
+	C_AuthBindTempKey:                {"AuthBindTempKey", func() Message { return &AuthBindTempKey{} }},
+	C_AuthBindTempKeyInner:           {"AuthBindTempKeyInner", func() Message { return &AuthBindTempKeyInner{} }},
+}
+
+// ConstructorName returns the name of the constructor, the unknown constructors are named by their number
+func ConstructorName(constructor int64) string {
+	if c, ok := constructors[constructor]; ok {
+		return c.name
+	}
+	return strconv.FormatInt(constructor, 10)
+}
+
+// ConstructorByName returns the constructor of the name, the numbers are accepted as well
+func ConstructorByName(name string) (int64, bool) {
+	for constructor, c := range constructors {
+		if c.name == name {
+			return constructor, true
+		}
+	}
+	constructor, err := strconv.ParseInt(name, 10, 64)
+	return constructor, err == nil
+}
+
+// NewMessage returns an empty message of the constructor, it returns false if the constructor is unknown
+// or has no message of its own
+func NewMessage(constructor int64) (Message, bool) {
+	c, ok := constructors[constructor]
+	if !ok || c.newMessage == nil {
+		return nil, false
+	}
+	return c.newMessage(), true
+}
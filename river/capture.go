@@ -0,0 +1,264 @@
+package river
+
+import (
+	"crypto/rand"
+	"strconv"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/crypto/argon2"
+)
+
+// CaptureVersion is the version of the capture files which are written
+const CaptureVersion = 1
+
+// DefaultCaptureFrames is the number of the latest frames which are kept if the caller does not set it
+const DefaultCaptureFrames = 10000
+
+// Directions of the captured frames
+const (
+	CaptureInbound  = "in"
+	CaptureOutbound = "out"
+)
+
+// Modes of the capture files
+// CapturePlain keeps the auth keys of the frames, so the file is as secret as the keys.
+// CaptureRedacted drops the keys, its encrypted frames are only replayed by the keys of a connection info.
+// CaptureEncrypted keeps the keys, and seals the whole capture by a key which is derived from a passphrase.
+const (
+	CapturePlain     = "plain"
+	CaptureRedacted  = "redacted"
+	CaptureEncrypted = "encrypted"
+)
+
+// Argon2id parameters of the key of the encrypted captures
+const (
+	captureKDFIterations  = 3
+	captureKDFMemory      = 32 * 1024
+	captureKDFParallelism = 1
+)
+
+// CaptureFile
+// Frames are in the order they are decoded or encoded. An encrypted capture keeps the sealed JSON of its
+// plain capture in Sealed, the other fields of the plain capture are left empty.
+type CaptureFile struct {
+	Version   int
+	Mode      string
+	Handle    string
+	StartedAt int64
+	Dropped   int          `json:",omitempty"`
+	Keys      []CaptureKey `json:",omitempty"`
+	Frames    []CaptureFrame
+	KDF       *CaptureKDF `json:",omitempty"`
+	Nonce     []byte      `json:",omitempty"`
+	Sealed    []byte      `json:",omitempty"`
+}
+
+// CaptureKey
+type CaptureKey struct {
+	AuthID  string
+	AuthKey []byte
+}
+
+// CaptureFrame
+// Time is the local unix time in milliseconds when the frame is decoded or encoded
+type CaptureFrame struct {
+	Time      int64
+	Direction string
+	Frame     []byte
+}
+
+// CaptureKDF
+type CaptureKDF struct {
+	Salt        []byte
+	Iterations  uint32
+	Memory      uint32
+	Parallelism uint8
+}
+
+// capture
+// Frames which are recorded since StartCapture, only the latest maxFrames are kept
+type capture struct {
+	startedAt int64
+	maxFrames int
+	dropped   int
+	frames    []CaptureFrame
+	keys      map[int64][]byte
+}
+
+// StartCapture starts recording the frames which are decoded and encoded, with the auth keys they are
+// encrypted with. Only the latest maxFrames are kept, zero keeps DefaultCaptureFrames. A running capture
+// is discarded.
+func (r *River) StartCapture(maxFrames int) {
+	if maxFrames <= 0 {
+		maxFrames = DefaultCaptureFrames
+	}
+	r.capMtx.Lock()
+	r.capture = &capture{
+		startedAt: r.localTime(),
+		maxFrames: maxFrames,
+		keys:      make(map[int64][]byte),
+	}
+	r.capMtx.Unlock()
+}
+
+// StopCapture stops recording and returns the capture file of the mode, the passphrase is only used
+// by CaptureEncrypted
+func (r *River) StopCapture(mode string, passphrase []byte) ([]byte, error) {
+	if err := CheckCaptureMode(mode, passphrase); err != nil {
+		return nil, err
+	}
+	r.capMtx.Lock()
+	c := r.capture
+	r.capture = nil
+	r.capMtx.Unlock()
+	if c == nil {
+		return nil, _errors.ErrNoCapture
+	}
+
+	f := CaptureFile{
+		Version:   CaptureVersion,
+		Mode:      CapturePlain,
+		Handle:    r.handle,
+		StartedAt: c.startedAt,
+		Dropped:   c.dropped,
+		Frames:    c.frames,
+	}
+	if mode == CaptureRedacted {
+		f.Mode = CaptureRedacted
+		return f.MarshalJSON()
+	}
+	for authID, authKey := range c.keys {
+		f.Keys = append(f.Keys, CaptureKey{
+			AuthID:  strconv.FormatInt(authID, 10),
+			AuthKey: authKey,
+		})
+	}
+	if mode == CapturePlain {
+		return f.MarshalJSON()
+	}
+	return sealCapture(&f, passphrase)
+}
+
+// CheckCaptureMode returns the error of StopCapture for the mode and the passphrase, if it would fail by them
+func CheckCaptureMode(mode string, passphrase []byte) error {
+	switch mode {
+	case CapturePlain, CaptureRedacted:
+		return nil
+	case CaptureEncrypted:
+		if len(passphrase) == 0 {
+			return _errors.ErrCapturePassphrase
+		}
+		return nil
+	}
+	return _errors.ErrUnknownCaptureMode
+}
+
+// recordFrame records the frame if a capture is running, the frame is copied since the callers could reuse it
+func (r *River) recordFrame(direction string, frame []byte) {
+	r.capMtx.Lock()
+	defer r.capMtx.Unlock()
+	c := r.capture
+	if c == nil {
+		return
+	}
+	if len(c.frames) >= c.maxFrames {
+		c.frames = c.frames[1:]
+		c.dropped++
+	}
+	c.frames = append(c.frames, CaptureFrame{
+		Time:      r.localTime(),
+		Direction: direction,
+		Frame:     append([]byte(nil), frame...),
+	})
+}
+
+// recordKey keeps the auth key which a recorded frame is encrypted with
+func (r *River) recordKey(authID int64, authKey []byte) {
+	if authID == 0 || len(authKey) == 0 {
+		return
+	}
+	r.capMtx.Lock()
+	defer r.capMtx.Unlock()
+	if c := r.capture; c != nil && c.keys[authID] == nil {
+		c.keys[authID] = append([]byte(nil), authKey...)
+	}
+}
+
+// sealCapture returns the encrypted capture file which seals the plain capture f
+func sealCapture(f *CaptureFile, passphrase []byte) ([]byte, error) {
+	plain, err := f.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	kdf := &CaptureKDF{
+		Salt:        make([]byte, 32),
+		Iterations:  captureKDFIterations,
+		Memory:      captureKDFMemory,
+		Parallelism: captureKDFParallelism,
+	}
+	nonce := make([]byte, 12)
+	if _, err = rand.Read(kdf.Salt); err != nil {
+		return nil, err
+	}
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed, err := utils.AES256GCMEncrypt(kdf.key(passphrase), nonce, plain)
+	if err != nil {
+		return nil, err
+	}
+	out := CaptureFile{
+		Version:   CaptureVersion,
+		Mode:      CaptureEncrypted,
+		Handle:    f.Handle,
+		StartedAt: f.StartedAt,
+		KDF:       kdf,
+		Nonce:     nonce,
+		Sealed:    sealed,
+	}
+	return out.MarshalJSON()
+}
+
+// OpenCapture parses the capture file, the encrypted captures are opened by the passphrase
+func OpenCapture(data, passphrase []byte) (*CaptureFile, error) {
+	f := &CaptureFile{}
+	if err := f.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	if f.Version != CaptureVersion {
+		return nil, _errors.ErrInvalidCapture
+	}
+	switch f.Mode {
+	case CapturePlain, CaptureRedacted:
+		return f, nil
+	case CaptureEncrypted:
+	default:
+		return nil, _errors.ErrUnknownCaptureMode
+	}
+
+	kdf := f.KDF
+	if kdf == nil || len(kdf.Salt) == 0 || len(f.Nonce) != 12 ||
+		kdf.Iterations == 0 || kdf.Iterations > argon2MaxIterations ||
+		kdf.Parallelism == 0 || kdf.Parallelism > argon2MaxParallelism ||
+		kdf.Memory < 8*uint32(kdf.Parallelism) || kdf.Memory > argon2MaxMemory {
+		return nil, _errors.ErrInvalidCapture
+	}
+	plain, err := utils.AES256GCMDecrypt(kdf.key(passphrase), f.Nonce, f.Sealed)
+	if err != nil {
+		return nil, _errors.ErrCapturePassphrase
+	}
+	inner := &CaptureFile{}
+	if err = inner.UnmarshalJSON(plain); err != nil {
+		return nil, err
+	}
+	if inner.Version != CaptureVersion || inner.Mode != CapturePlain {
+		return nil, _errors.ErrInvalidCapture
+	}
+	return inner, nil
+}
+
+// key derives the AES-256 key of the encrypted capture from the passphrase
+func (kdf *CaptureKDF) key(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, kdf.Salt, kdf.Iterations, kdf.Memory, kdf.Parallelism, 32)
+}
@@ -0,0 +1,206 @@
+package river
+
+import (
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+)
+
+// MarshalJSON implements json.Marshaler
+func (v CaptureFile) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Version")
+	w.Int64(int64(v.Version))
+	w.Field("Mode")
+	w.String(v.Mode)
+	w.Field("Handle")
+	w.String(v.Handle)
+	w.Field("StartedAt")
+	w.Int64(v.StartedAt)
+	if v.Dropped != 0 {
+		w.Field("Dropped")
+		w.Int64(int64(v.Dropped))
+	}
+	if len(v.Keys) != 0 {
+		w.Field("Keys")
+		w.Array(false, len(v.Keys), func(i int) { v.Keys[i].writeJSON(&w) })
+	}
+	w.Field("Frames")
+	w.Array(v.Frames == nil, len(v.Frames), func(i int) { v.Frames[i].writeJSON(&w) })
+	if v.KDF != nil {
+		w.Field("KDF")
+		v.KDF.writeJSON(&w)
+	}
+	if len(v.Nonce) != 0 {
+		w.Field("Nonce")
+		w.Base64(v.Nonce)
+	}
+	if len(v.Sealed) != 0 {
+		w.Field("Sealed")
+		w.Base64(v.Sealed)
+	}
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CaptureFile) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Version":
+			v.Version = l.Int()
+		case "Mode":
+			v.Mode = l.String()
+		case "Handle":
+			v.Handle = l.String()
+		case "StartedAt":
+			v.StartedAt = l.Int64()
+		case "Dropped":
+			v.Dropped = l.Int()
+		case "Keys":
+			v.Keys = []CaptureKey{}
+			l.Array(func() {
+				x := CaptureKey{}
+				x.readJSON(l)
+				v.Keys = append(v.Keys, x)
+			})
+		case "Frames":
+			v.Frames = []CaptureFrame{}
+			l.Array(func() {
+				x := CaptureFrame{}
+				x.readJSON(l)
+				v.Frames = append(v.Frames, x)
+			})
+		case "KDF":
+			v.KDF = &CaptureKDF{}
+			v.KDF.readJSON(l)
+		case "Nonce":
+			v.Nonce = l.Bytes()
+		case "Sealed":
+			v.Sealed = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v CaptureKey) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CaptureKey) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v CaptureKey) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("AuthKey")
+	w.Base64(v.AuthKey)
+	w.ObjectEnd()
+}
+
+func (v *CaptureKey) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "AuthID":
+			v.AuthID = l.String()
+		case "AuthKey":
+			v.AuthKey = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v CaptureFrame) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CaptureFrame) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v CaptureFrame) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Time")
+	w.Int64(v.Time)
+	w.Field("Direction")
+	w.String(v.Direction)
+	w.Field("Frame")
+	w.Base64(v.Frame)
+	w.ObjectEnd()
+}
+
+func (v *CaptureFrame) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Time":
+			v.Time = l.Int64()
+		case "Direction":
+			v.Direction = l.String()
+		case "Frame":
+			v.Frame = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v CaptureKDF) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CaptureKDF) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v CaptureKDF) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Salt")
+	w.Base64(v.Salt)
+	w.Field("Iterations")
+	w.Uint64(uint64(v.Iterations))
+	w.Field("Memory")
+	w.Uint64(uint64(v.Memory))
+	w.Field("Parallelism")
+	w.Uint64(uint64(v.Parallelism))
+	w.ObjectEnd()
+}
+
+func (v *CaptureKDF) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Salt":
+			v.Salt = l.Bytes()
+		case "Iterations":
+			v.Iterations = l.Uint32()
+		case "Memory":
+			v.Memory = l.Uint32()
+		case "Parallelism":
+			v.Parallelism = l.Uint8()
+		default:
+			l.Skip()
+		}
+	})
+}
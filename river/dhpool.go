@@ -0,0 +1,119 @@
+package river
+
+import (
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	"math/big"
+	"sync"
+)
+
+// DefaultDHPoolSize is the number of key pairs which are kept ready for each DH group
+const DefaultDHPoolSize = 2
+
+// dhPool
+// Key pairs of the DH groups are generated in the background, since g^x mod p is the slowest part of
+// AuthStep2 on the low-end devices. Each pair is handed out once and then removed from the pool.
+type dhPool struct {
+	mtx     sync.Mutex
+	size    int
+	pairs   map[int64][]*dhKeyExchange
+	filling bool
+	// closed stops the filling when the account is removed
+	closed bool
+}
+
+// take removes a key pair of the group from the pool, it returns nil if the pool is empty. The pairs of
+// another prime or generator under the same fingerprint are dropped.
+func (pool *dhPool) take(fingerPrint int64, p, g *big.Int) *dhKeyExchange {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+	pairs := pool.pairs[fingerPrint]
+	for len(pairs) > 0 {
+		kex := pairs[len(pairs)-1]
+		pairs[len(pairs)-1] = nil
+		pairs = pairs[:len(pairs)-1]
+		// the group is changed since the pair is generated
+		if kex.p.Cmp(p) != 0 || kex.g.Cmp(g) != 0 {
+			continue
+		}
+		pool.pairs[fingerPrint] = pairs
+		return kex
+	}
+	delete(pool.pairs, fingerPrint)
+	return nil
+}
+
+// SetDHPoolSize sets the number of key pairs which are kept ready for each DH group, zero disables the pool
+func (r *River) SetDHPoolSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	r.dhPool.mtx.Lock()
+	r.dhPool.size = size
+	for fp, pairs := range r.dhPool.pairs {
+		if len(pairs) > size {
+			r.dhPool.pairs[fp] = pairs[:size]
+		}
+	}
+	r.dhPool.mtx.Unlock()
+	r.WarmDHPool()
+}
+
+// WarmDHPool fills the pool with key pairs of the DH groups of ServerKeys in the background
+func (r *River) WarmDHPool() {
+	keys := r.getServerKeys()
+	r.dhPool.mtx.Lock()
+	defer r.dhPool.mtx.Unlock()
+	if r.dhPool.closed || r.dhPool.filling || r.dhPool.size == 0 || len(keys.DHGroups) == 0 {
+		return
+	}
+	r.dhPool.filling = true
+	go r.fillDHPool(keys)
+}
+
+// fillDHPool fills the pool by the snapshot of the server keys, the pairs of a group which is
+// replaced later are dropped by take
+func (r *River) fillDHPool(keys river_conn.ServerKeys) {
+	defer func() {
+		r.dhPool.mtx.Lock()
+		r.dhPool.filling = false
+		r.dhPool.mtx.Unlock()
+	}()
+
+	for _, g := range keys.DHGroups {
+		p, gen, err := getDHGroup(&keys, g.FingerPrint)
+		if err != nil {
+			continue
+		}
+		for {
+			r.dhPool.mtx.Lock()
+			full := len(r.dhPool.pairs[g.FingerPrint]) >= r.dhPool.size
+			closed := r.dhPool.closed
+			r.dhPool.mtx.Unlock()
+			if closed {
+				return
+			}
+			if full {
+				break
+			}
+
+			kex, err := newDHKeyExchange(p, gen, nil)
+			if err != nil {
+				break
+			}
+
+			r.dhPool.mtx.Lock()
+			if !r.dhPool.closed && len(r.dhPool.pairs[g.FingerPrint]) < r.dhPool.size {
+				r.dhPool.pairs[g.FingerPrint] = append(r.dhPool.pairs[g.FingerPrint], kex)
+			}
+			r.dhPool.mtx.Unlock()
+		}
+	}
+}
+
+// closeDHPool stops the filling after the pair which is being generated and drops the pairs
+func (r *River) closeDHPool() {
+	r.dhPool.mtx.Lock()
+	r.dhPool.closed = true
+	r.dhPool.pairs = make(map[int64][]*dhKeyExchange)
+	r.dhPool.mtx.Unlock()
+}
@@ -0,0 +1,74 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// TestWarmDHPoolWhileLoading loads the server keys again while the pool is filled, go test -race
+// reports the server keys which are read without r.mtx
+func TestWarmDHPoolWhileLoading(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "dhpool")
+	r.WarmDHPool()
+	for i := 0; i < 20; i++ {
+		if err := r.Load("{}", s.ServerKeys()); err != _errors.ErrNoAuthKey {
+			t.Fatal(err)
+		}
+		r.WarmDHPool()
+	}
+	waitDHPool(t, r)
+	keys := r.getServerKeys()
+	for _, g := range keys.DHGroups {
+		if n := len(r.dhPool.pairs[g.FingerPrint]); n != DefaultDHPoolSize {
+			t.Errorf("%d pairs of the group %d, expected %d", n, g.FingerPrint, DefaultDHPoolSize)
+		}
+	}
+}
+
+// waitDHPool waits until the pool is not filled anymore
+func waitDHPool(t *testing.T, r *River) {
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		r.dhPool.mtx.Lock()
+		filling := r.dhPool.filling
+		r.dhPool.mtx.Unlock()
+		if !filling {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the pool is still filled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestDHPoolTakeGroup takes the pairs of a fingerprint which group is changed, only a pair of the same
+// prime and generator is handed out
+func TestDHPoolTakeGroup(t *testing.T) {
+	p, two, five := big.NewInt(23), big.NewInt(2), big.NewInt(5)
+	same := &dhKeyExchange{p: p, g: two}
+	pool := dhPool{pairs: map[int64][]*dhKeyExchange{
+		1: {same, {p: p, g: five}, {p: big.NewInt(47), g: two}},
+	}}
+	if kex := pool.take(1, p, two); kex != same {
+		t.Fatalf("took %+v, expected the pair of the same group", kex)
+	}
+	if len(pool.pairs[1]) != 0 {
+		t.Fatalf("%d pairs of the other groups are kept", len(pool.pairs[1]))
+	}
+
+	pool.pairs[1] = []*dhKeyExchange{{p: p, g: five}}
+	if kex := pool.take(1, p, two); kex != nil {
+		t.Fatalf("took the pair of the generator %v", kex.g)
+	}
+	if kex := pool.take(2, p, two); kex != nil {
+		t.Fatal("took a pair of an empty fingerprint")
+	}
+}
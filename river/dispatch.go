@@ -0,0 +1,53 @@
+package river
+
+import (
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// Dispatcher receives the envelopes which Dispatch unpacks from a decoded envelope
+type Dispatcher interface {
+	// OnUpdate receives the UpdateContainer, its max update id is already set
+	OnUpdate(data []byte)
+	// OnMessage receives the responses and any other envelope
+	OnMessage(requestID uint64, constructor int64, data []byte)
+}
+
+// Dispatch unpacks the containers of the envelope and delivers their envelopes to d in order. The envelopes
+// of a container which could not be unpacked are dropped, the first error is returned after the others
+// are delivered. The containers nested deeper than MaxContainerDepth are dropped by ErrContainerTooDeep.
+func (r *River) Dispatch(env *msg.MessageEnvelope, d Dispatcher) (err error) {
+	defer recoverMalformed(&err)
+	return r.dispatch(env, d, 0)
+}
+
+func (r *River) dispatch(env *msg.MessageEnvelope, d Dispatcher, depth int) (err error) {
+	switch env.Constructor {
+	case msg.C_MessageContainer:
+		if depth >= MaxContainerDepth {
+			return _errors.ErrContainerTooDeep
+		}
+		x := new(msg.MessageContainer)
+		if err = x.Unmarshal(env.Message); err != nil {
+			return
+		}
+		for _, envelope := range x.Envelopes {
+			if envelope == nil {
+				continue
+			}
+			if e := r.dispatch(envelope, d, depth+1); e != nil && err == nil {
+				err = e
+			}
+		}
+	case msg.C_UpdateContainer:
+		x := new(msg.UpdateContainer)
+		if err = x.Unmarshal(env.Message); err != nil {
+			return
+		}
+		r.SetUpdateID(x.MaxUpdateID)
+		d.OnUpdate(env.Message)
+	default:
+		d.OnMessage(env.RequestID, env.Constructor, env.Message)
+	}
+	return
+}
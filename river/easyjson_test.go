@@ -0,0 +1,92 @@
+package river
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// easyjsonSample
+// A value which JSON is kept in testdata/easyjson as easyjson wrote it, decoded is a pointer to the zero
+// value of its type
+type easyjsonSample struct {
+	name    string
+	value   interface{ MarshalJSON() ([]byte, error) }
+	decoded interface{ UnmarshalJSON([]byte) error }
+	encodes bool
+}
+
+// easyjsonText has the characters which are escaped
+const easyjsonText = "quote \" backslash \\ <tag> & amp \u2028 \u2029 \x01\t\n\r سلام 😀 /"
+
+func easyjsonBytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func easyjsonSamples() []easyjsonSample {
+	return []easyjsonSample{
+		{"CaptureFile", CaptureFile{
+			Version:   CaptureVersion,
+			Mode:      CapturePlain,
+			Handle:    easyjsonText,
+			StartedAt: 1700000000123,
+			Dropped:   3,
+			Keys:      []CaptureKey{{AuthID: "-9223372036854775808", AuthKey: easyjsonBytes(256)}, {}},
+			Frames: []CaptureFrame{
+				{Time: 1700000000124, Direction: CaptureInbound, Frame: easyjsonBytes(33)},
+				{Time: -1, Direction: CaptureOutbound, Frame: []byte{}},
+			},
+			KDF:    &CaptureKDF{Salt: easyjsonBytes(16), Iterations: math.MaxUint32, Memory: 65536, Parallelism: math.MaxUint8},
+			Nonce:  easyjsonBytes(12),
+			Sealed: easyjsonBytes(7),
+		}, &CaptureFile{}, true},
+		{"CaptureFileEmpty", CaptureFile{}, &CaptureFile{}, true},
+		{"CaptureFileEmptyFrames", CaptureFile{Frames: []CaptureFrame{}, KDF: &CaptureKDF{}}, &CaptureFile{}, true},
+		{"SelfTestVectors", SelfTestVectors{
+			Version: 3,
+			Ciphers: []CipherVector{{AuthKey: easyjsonBytes(256), Plain: []byte{}, MessageKey: easyjsonBytes(32),
+				Encrypted: easyjsonBytes(48)}},
+			SplitPQ:  []SplitPQVector{{PQ: "1724114033281923457", P: "1229739323", Q: "1402015859"}, {}},
+			AuthKeys: []AuthKeyVector{{AuthKey: easyjsonBytes(256), SecretNonce: easyjsonBytes(16), AuthID: "-1", SecretHash: "18446744073709551615"}},
+			SrpHashes: []SrpHashVector{{Algorithm: -4, AlgorithmData: easyjsonBytes(300), Password: []byte(easyjsonText),
+				Hash: easyjsonBytes(256)}},
+			InputPasswords: []InputPasswordVector{{Password: []byte("p"), AccountPassword: easyjsonBytes(20),
+				Random: easyjsonBytes(256), InputPassword: easyjsonBytes(40), M2: easyjsonBytes(32)}},
+		}, &SelfTestVectors{}, true},
+		{"SelfTestVectorsEmpty", SelfTestVectors{}, &SelfTestVectors{}, true},
+	}
+}
+
+// TestEasyjsonEquivalence checks jsonx writes the JSON which easyjson wrote for the same values, and
+// decodes it back to them
+func TestEasyjsonEquivalence(t *testing.T) {
+	for _, s := range easyjsonSamples() {
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", "easyjson", s.name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.encodes {
+			data, err := s.value.MarshalJSON()
+			if err != nil {
+				t.Fatalf("%s: %v", s.name, err)
+			}
+			if !bytes.Equal(data, expected) {
+				t.Errorf("%s is written as\n%s\neasyjson wrote\n%s", s.name, data, expected)
+			}
+		}
+		if err = s.decoded.UnmarshalJSON(expected); err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		if decoded := reflect.ValueOf(s.decoded).Elem().Interface(); !reflect.DeepEqual(decoded, s.value) {
+			t.Errorf("%s is decoded as\n%#v\nexpected\n%#v", s.name, decoded, s.value)
+		}
+	}
+}
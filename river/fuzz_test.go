@@ -0,0 +1,171 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// update writes the capture of a stub session and the fuzz corpus of its frames again
+var update = flag.Bool("update", false, "write testdata/capture.json and the fuzz corpus again")
+
+const testCaptureFile = "testdata/capture.json"
+
+// The fuzz targets fail on ErrMalformedInput, it is a panic which is recovered
+func FuzzDecode(f *testing.F) {
+	capture := loadTestCapture(f)
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		r := captureRiver(t, capture)
+		if _, err := r.Decode(frame); err == _errors.ErrMalformedInput {
+			t.Fatal("Decode panicked")
+		}
+	})
+}
+
+func FuzzDispatch(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		env := &msg.MessageEnvelope{}
+		if env.Unmarshal(data) != nil {
+			return
+		}
+		if err := NewRiver("fuzz").Dispatch(env, &testDispatcher{}); err == _errors.ErrMalformedInput {
+			t.Fatal("Dispatch panicked")
+		}
+	})
+}
+
+func loadTestCapture(t testing.TB) *CaptureFile {
+	data, err := ioutil.ReadFile(testCaptureFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenCapture(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// captureRiver returns an account which decrypts the frames by the keys of the capture
+func captureRiver(t testing.TB, f *CaptureFile) *River {
+	conn, err := river_conn.NewRiverConnection("fuzz", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, k := range f.Keys {
+		authID, err := strconv.ParseInt(k.AuthID, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var authKey [256]byte
+		copy(authKey[:], k.AuthKey)
+		conn.SetClusterKey(int32(idx+1), authID, authKey)
+	}
+	r := NewRiver("fuzz")
+	r.ConnInfo = conn
+	return r
+}
+
+// TestWriteCapture records a session with the stub: the handshake, SystemGetServerTime, an Error reply,
+// an UpdateContainer and a MessageContainer which nests another one
+func TestWriteCapture(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to write the capture again")
+	}
+	s := newTestStub(t)
+	r := s.newRiver(t, "capture")
+	r.StartCapture(0)
+	c := s.dial(t, r)
+	if err := c.auth(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.expect(msg.C_SystemGetServerTime, nil, msg.C_SystemServerTime); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := (&msg.Error{Code: "E01", Items: "CAPTURE"}).Marshal()
+	if _, err := c.expect(msg.C_Error, body, msg.C_Error); err != nil {
+		t.Fatal(err)
+	}
+
+	updates, _ := (&msg.UpdateContainer{
+		Length:      1,
+		Updates:     []*msg.UpdateEnvelope{{Constructor: msg.C_SystemServerTime, UpdateID: 7, Timestamp: 1}},
+		MinUpdateID: 7,
+		MaxUpdateID: 7,
+	}).Marshal()
+	inner, _ := (&msg.MessageContainer{Length: 1, Envelopes: []*msg.MessageEnvelope{
+		{Constructor: msg.C_Error, RequestID: 2, Message: body},
+	}}).Marshal()
+	outer, _ := (&msg.MessageContainer{Length: 2, Envelopes: []*msg.MessageEnvelope{
+		{Constructor: msg.C_Error, RequestID: 1, Message: body},
+		{Constructor: msg.C_MessageContainer, Message: inner},
+	}}).Marshal()
+	for _, env := range []*msg.MessageEnvelope{
+		{Constructor: msg.C_UpdateContainer, Message: updates},
+		{Constructor: msg.C_MessageContainer, Message: outer},
+	} {
+		if err := s.Push(r.authID, env); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.receive(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := r.StopCapture(CapturePlain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(testCaptureFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeCorpus(t, r, data)
+}
+
+// writeCorpus writes the inbound frames of the capture as the corpus of FuzzDecode, and their envelopes
+// and the containers which are nested too deeply as the corpus of FuzzDispatch
+func writeCorpus(t *testing.T, r *River, data []byte) {
+	f, err := OpenCapture(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"FuzzDecode", "FuzzDispatch"} {
+		_ = os.RemoveAll(filepath.Join("testdata", "fuzz", dir))
+		if err = os.MkdirAll(filepath.Join("testdata", "fuzz", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for idx, frame := range f.Frames {
+		if frame.Direction != CaptureInbound {
+			continue
+		}
+		env, err := r.Decode(frame.Frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		envBytes, _ := env.Marshal()
+		name := fmt.Sprintf("frame-%02d-%s", idx, msg.ConstructorName(env.Constructor))
+		writeCorpusFile(t, "FuzzDecode", name, frame.Frame)
+		writeCorpusFile(t, "FuzzDispatch", name, envBytes)
+	}
+	tooDeep, _ := nestedContainers(MaxContainerDepth+1, &msg.MessageEnvelope{Constructor: msg.C_Error}).Marshal()
+	writeCorpusFile(t, "FuzzDispatch", "too-deep", tooDeep)
+}
+
+func writeCorpusFile(t *testing.T, target, name string, data []byte) {
+	content := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", data)
+	if err := ioutil.WriteFile(filepath.Join("testdata", "fuzz", target, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
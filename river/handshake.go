@@ -0,0 +1,199 @@
+package river
+
+import (
+	"context"
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HandshakeStep
+// The handshake moves Idle -> Connecting (AuthStep1) -> Completing (AuthStep2) -> Done (AuthStep3).
+// AuthStep1 could restart the handshake from any step.
+type HandshakeStep int
+
+const (
+	HandshakeIdle HandshakeStep = iota
+	HandshakeConnecting
+	HandshakeCompleting
+	HandshakeDone
+)
+
+const (
+	// handshakeTTL is the number of seconds which server keeps the nonces of an unfinished handshake
+	handshakeTTL = 5 * 60
+	// splitPQTimeout bounds the PQ factorization of AuthStep2
+	splitPQTimeout = 30 * time.Second
+)
+
+// handshake
+// State of the auth key negotiation between the steps
+type handshake struct {
+	mtx           sync.Mutex
+	step          HandshakeStep
+	clusterID     int32
+	tempTTL       int32
+	clientNonce   uint64
+	serverNonce   uint64
+	dhFingerPrint int64
+	kex           keyExchange
+	secretNonce   []byte
+	request       []byte
+	createdAt     int64 // local time of InitConnect in seconds, the clock sync of step 2 does not move the TTL
+	sentAt        int64 // local time of InitConnect in milliseconds, for the clock sync
+	// cancel stops the running step, it is guarded by River.hsMtx since hs.mtx is held by the step
+	cancel context.CancelFunc
+}
+
+// expect returns error if the handshake is not in the step or it is expired
+func (hs *handshake) expect(step HandshakeStep, now int64) error {
+	if hs == nil || hs.step != step {
+		return _errors.ErrHandshakeStep
+	}
+	if hs.createdAt+handshakeTTL < now {
+		return _errors.ErrHandshakeExpired
+	}
+	return nil
+}
+
+// HandshakeStep returns the current step of the handshake which is started by id
+func (r *River) HandshakeStep(id int64) HandshakeStep {
+	hs := r.getHandshake(id)
+	if hs == nil {
+		return HandshakeIdle
+	}
+	hs.mtx.Lock()
+	defer hs.mtx.Unlock()
+	return hs.step
+}
+
+func (r *River) getHandshake(id int64) *handshake {
+	r.hsMtx.Lock()
+	defer r.hsMtx.Unlock()
+	return r.handshakes[id]
+}
+
+// putHandshake replaces the handshake of id and drops the expired ones
+func (r *River) putHandshake(id int64, hs *handshake) {
+	r.hsMtx.Lock()
+	defer r.hsMtx.Unlock()
+	for hid, h := range r.handshakes {
+		if h.createdAt+handshakeTTL < hs.createdAt {
+			delete(r.handshakes, hid)
+		}
+	}
+	r.handshakes[id] = hs
+}
+
+// withCancel returns the context of the running step of the handshake which is stopped by CancelHandshake
+func (r *River) withCancel(hs *handshake, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	r.hsMtx.Lock()
+	hs.cancel = cancel
+	r.hsMtx.Unlock()
+	return ctx, func() {
+		r.hsMtx.Lock()
+		hs.cancel = nil
+		r.hsMtx.Unlock()
+		cancel()
+	}
+}
+
+// CancelHandshake stops the running step of the handshake which is started by id and forgets it
+func (r *River) CancelHandshake(id int64) {
+	r.hsMtx.Lock()
+	if hs := r.handshakes[id]; hs != nil && hs.cancel != nil {
+		hs.cancel()
+	}
+	delete(r.handshakes, id)
+	r.hsMtx.Unlock()
+	r.clearHandshake(id)
+}
+
+func (r *River) removeHandshake(id int64) {
+	r.hsMtx.Lock()
+	delete(r.handshakes, id)
+	r.hsMtx.Unlock()
+	r.clearHandshake(id)
+}
+
+// saveHandshake persists the handshake after AuthStep2, the key exchange and the factorized PQ are the
+// expensive parts, so they are not repeated if the page reloads before AuthStep3. The private key and the
+// secret nonce are saved in plain next to the auth keys, they only derive the key of this handshake and
+// are removed once it is done, failed, cancelled or found expired after handshakeTTL.
+func (r *River) saveHandshake(id int64, hs *handshake) {
+	if hs.step != HandshakeCompleting {
+		return
+	}
+	v := river_conn.HandshakeJS{
+		Step:          int(hs.step),
+		ClusterID:     hs.clusterID,
+		TempTTL:       hs.tempTTL,
+		ClientNonce:   strconv.FormatUint(hs.clientNonce, 10),
+		ServerNonce:   strconv.FormatUint(hs.serverNonce, 10),
+		DHFingerPrint: strconv.FormatInt(hs.dhFingerPrint, 10),
+		PrivateKey:    hs.kex.PrivateKey(),
+		SecretNonce:   hs.secretNonce,
+		Request:       hs.request,
+		CreatedAt:     hs.createdAt,
+	}
+	bytes, err := v.MarshalJSON()
+	if err != nil {
+		return
+	}
+	river_conn.SaveHandshake(r.handle, id, bytes)
+}
+
+// clearHandshake removes the persisted handshake
+func (r *River) clearHandshake(id int64) {
+	river_conn.SaveHandshake(r.handle, id, nil)
+}
+
+// ResumeHandshake restores the handshake which is persisted after AuthStep2 and returns the
+// InitCompleteAuth request to be sent again, then the response goes to AuthStep3 as usual
+func (r *River) ResumeHandshake(id int64, data []byte) (request []byte, err error) {
+	// the saved secrets of a handshake which cannot be resumed are not kept
+	defer func() {
+		if err != nil {
+			r.clearHandshake(id)
+		}
+	}()
+	v := river_conn.HandshakeJS{}
+	err = v.UnmarshalJSON(data)
+	if err != nil {
+		return
+	}
+
+	hs := &handshake{
+		step:        HandshakeStep(v.Step),
+		clusterID:   v.ClusterID,
+		tempTTL:     v.TempTTL,
+		secretNonce: v.SecretNonce,
+		request:     v.Request,
+		createdAt:   v.CreatedAt,
+	}
+	if err = hs.expect(HandshakeCompleting, r.localTime()/1000); err != nil {
+		return
+	}
+	if hs.clientNonce, err = strconv.ParseUint(v.ClientNonce, 10, 64); err != nil {
+		return
+	}
+	if hs.serverNonce, err = strconv.ParseUint(v.ServerNonce, 10, 64); err != nil {
+		return
+	}
+	if hs.dhFingerPrint, err = strconv.ParseInt(v.DHFingerPrint, 10, 64); err != nil {
+		return
+	}
+	if len(v.PrivateKey) == 0 {
+		return nil, _errors.ErrInvalidPrivateKey
+	}
+	hs.kex, err = r.newKeyExchange(hs.dhFingerPrint, hs.clientNonce, hs.serverNonce, v.PrivateKey)
+	if err != nil {
+		return
+	}
+
+	r.putHandshake(id, hs)
+	return hs.request, nil
+}
@@ -0,0 +1,293 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// TestHandshakePadding runs the handshake with the RSA keys of both paddings, then an encrypted request
+// is answered by the auth key which the stub computed
+func TestHandshakePadding(t *testing.T) {
+	for _, padding := range []string{river_conn.PaddingPKCS1v15, river_conn.PaddingOAEPSHA256} {
+		s := newTestStubWithPadding(t, padding)
+		r := s.newRiver(t, "padding")
+		c := s.dial(t, r)
+		if err := c.auth(1); err != nil {
+			t.Fatalf("padding %q: %v", padding, err)
+		}
+		if r.authID == 0 || r.ConnInfo.AuthID != r.authID {
+			t.Fatalf("padding %q: the auth key is not set", padding)
+		}
+		if _, err := c.expect(msg.C_Error, nil, msg.C_Error); err != nil {
+			t.Fatalf("padding %q: %v", padding, err)
+		}
+	}
+}
+
+// TestHandshakeOtherCluster runs the handshake with a cluster which has no key yet, the key is kept
+// for that cluster and encrypts its requests
+func TestHandshakeOtherCluster(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "cluster")
+	c := s.dial(t, r)
+	c.clusterID = 2
+	if err := c.auth(1); err != nil {
+		t.Fatal(err)
+	}
+	authID, _, err := r.ConnInfo.GetClusterKey(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = r.ConnInfo.GetClusterKey(river_conn.DefaultClusterID); err != _errors.ErrNoAuthKey {
+		t.Fatalf("the default cluster has a key: %v", err)
+	}
+	res, err := c.call(msg.C_Error, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Constructor != msg.C_Error {
+		t.Fatalf("reply is %s", msg.ConstructorName(res.Constructor))
+	}
+	frame, err := r.EncodeFor(2, &msg.MessageEnvelope{Constructor: msg.C_Error})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := msg.ProtoMessage{}
+	if err = m.Unmarshal(frame); err != nil || m.AuthID != authID {
+		t.Fatalf("the request of cluster 2 is encrypted by %d, expected %d", m.AuthID, authID)
+	}
+	if _, err = r.EncodeFor(3, &msg.MessageEnvelope{Constructor: msg.C_Error}); err != _errors.ErrNoAuthKey {
+		t.Fatalf("a request of cluster 3 is encoded by %v", err)
+	}
+}
+
+// TestHandshakeClockSync starts the handshake by a clock which is years behind the server, the sync of
+// step 2 must not expire it
+func TestHandshakeClockSync(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "clock")
+	r.ConnInfo.SetServerTime(1700000000)
+	if err := s.dial(t, r).auth(1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOverlappingHandshakes runs every step of several handshakes at once, each on its own connection,
+// go test -race reports the state which is shared between the handshakes
+func TestOverlappingHandshakes(t *testing.T) {
+	const n = 4
+	s := newTestStub(t)
+	r := s.newRiver(t, "overlap")
+	conns := make([]*testConn, n)
+	for i := range conns {
+		conns[i] = s.dial(t, r)
+	}
+	progress := func(int64) {}
+	replies := make([][]byte, n)
+	// each step is run by all the handshakes before any of them goes on to the next one
+	steps := []func(i int, id int64) error{
+		func(i int, id int64) (err error) {
+			replies[i], err = conns[i].expect(msg.C_InitConnect, r.AuthStep1(id, river_conn.DefaultClusterID, progress), msg.C_InitResponse)
+			return
+		},
+		func(i int, id int64) error {
+			req, err := r.AuthStep2(id, replies[i], progress)
+			if err != nil {
+				return err
+			}
+			replies[i], err = conns[i].expect(msg.C_InitCompleteAuth, req, msg.C_InitAuthCompleted)
+			return err
+		},
+		func(i int, id int64) error {
+			_, err := r.AuthStep3(id, replies[i], progress)
+			return err
+		},
+	}
+	for step, run := range steps {
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = run(i, int64(i+1))
+			}(i)
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("step %d of the handshake %d: %v", step+1, i+1, err)
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if st := r.HandshakeStep(int64(i + 1)); st != HandshakeIdle {
+			t.Errorf("the handshake %d is left in the step %v", i+1, st)
+		}
+	}
+	// the key of the handshake which finished last is kept, and the stub knows it
+	if r.authID == 0 || r.authID != r.ConnInfo.AuthID {
+		t.Fatal("the auth key is not set")
+	}
+	if _, err := conns[0].expect(msg.C_Error, nil, msg.C_Error); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHandshakeStepOrder calls the steps out of their order
+func TestHandshakeStepOrder(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "order")
+	c := s.dial(t, r)
+	progress := func(int64) {}
+
+	if _, err := r.AuthStep2(1, nil, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep2 before AuthStep1: %v", err)
+	}
+	if _, err := r.AuthStep3(1, nil, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep3 before AuthStep1: %v", err)
+	}
+	res, err := c.expect(msg.C_InitConnect, r.AuthStep1(1, river_conn.DefaultClusterID, progress), msg.C_InitResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.AuthStep3(1, res, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep3 before AuthStep2: %v", err)
+	}
+	// the handshake of another id is not affected by the one of 1
+	if _, err = r.AuthStep2(2, res, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep2 of another id: %v", err)
+	}
+	req, err := r.AuthStep2(1, res, progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.AuthStep2(1, res, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep2 twice: %v", err)
+	}
+	if res, err = c.expect(msg.C_InitCompleteAuth, req, msg.C_InitAuthCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.AuthStep3(1, res, progress); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.AuthStep3(1, res, progress); err != _errors.ErrHandshakeStep {
+		t.Fatalf("AuthStep3 after the handshake is done: %v", err)
+	}
+}
+
+// TestResumeHandshake saves the handshake after AuthStep2 and completes it by another account of the same
+// handle on another connection, as a page which reloads before AuthStep3
+func TestResumeHandshake(t *testing.T) {
+	s := newTestStub(t)
+	var (
+		mtx   sync.Mutex
+		saved = map[string]string{}
+	)
+	river_conn.SetStorage(func(data, key string) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if data == "" {
+			delete(saved, key)
+			return
+		}
+		saved[key] = data
+	})
+	defer river_conn.SetStorage(func(data, key string) {})
+	savedHandshake := func() string {
+		mtx.Lock()
+		defer mtx.Unlock()
+		for key, data := range saved {
+			if strings.HasPrefix(key, "river.handshake.resume.") {
+				return data
+			}
+		}
+		return ""
+	}
+	progress := func(int64) {}
+
+	r := s.newRiver(t, "resume")
+	res, err := s.dial(t, r).expect(msg.C_InitConnect, r.AuthStep1(1, river_conn.DefaultClusterID, progress), msg.C_InitResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.AuthStep2(1, res, progress); err != nil {
+		t.Fatal(err)
+	}
+	data := savedHandshake()
+	if data == "" {
+		t.Fatal("the handshake is not saved after AuthStep2")
+	}
+
+	// the handshake which is expired is not resumed, and it is removed
+	expired := s.newRiver(t, "resume")
+	expired.ConnInfo.SetLocalClock(func() int64 { return river_conn.LocalTime() + (handshakeTTL+1)*1000 })
+	if _, err = expired.ResumeHandshake(1, []byte(data)); err != _errors.ErrHandshakeExpired {
+		t.Fatalf("an expired handshake is resumed: %v", err)
+	}
+	if savedHandshake() != "" {
+		t.Fatal("the expired handshake is kept")
+	}
+
+	resumed := s.newRiver(t, "resume")
+	c := s.dial(t, resumed)
+	req, err := resumed.ResumeHandshake(1, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := resumed.HandshakeStep(1); st != HandshakeCompleting {
+		t.Fatalf("the resumed handshake is in the step %v", st)
+	}
+	if res, err = c.expect(msg.C_InitCompleteAuth, req, msg.C_InitAuthCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = resumed.AuthStep3(1, res, progress); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.authID == 0 || savedHandshake() != "" {
+		t.Fatal("the resumed handshake is not completed")
+	}
+	if _, err = c.expect(msg.C_Error, nil, msg.C_Error); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkHandshakeDH runs the handshakes of the 2048 bits DH group with the stub, the DH pool is
+// disabled so the key pairs are generated in the handshake
+func BenchmarkHandshakeDH(b *testing.B) {
+	s := newTestStub(b)
+	r := s.newRiver(b, "dh")
+	r.SetDHPoolSize(0)
+	// the stub selects X25519 whenever the client offers it
+	r.mtx.Lock()
+	r.serverKeys.ECDHGroups = nil
+	r.mtx.Unlock()
+	benchmarkHandshake(b, s, r)
+}
+
+// BenchmarkHandshakeX25519 runs the handshakes of X25519 with the stub
+func BenchmarkHandshakeX25519(b *testing.B) {
+	s := newTestStub(b)
+	r := s.newRiver(b, "x25519")
+	r.SetDHPoolSize(0)
+	benchmarkHandshake(b, s, r)
+}
+
+func benchmarkHandshake(b *testing.B, s *testStub, r *River) {
+	c := s.dial(b, r)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.auth(int64(i + 1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package river
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"math/big"
+	"sync"
+)
+
+const (
+	CurveX25519 = "X25519"
+	authKeySize = 256
+)
+
+// keyExchange
+// The key agreement which is used to negotiate the auth key in the handshake
+type keyExchange interface {
+	// PublicKey is sent to the server as InitCompleteAuth.ClientDHPubKey
+	PublicKey() []byte
+	// ComputeKey returns the 256 bytes auth key computed by the server's public key
+	ComputeKey(serverPubKey []byte) ([]byte, error)
+	// PrivateKey is persisted to restore the key exchange when the handshake is resumed
+	PrivateKey() []byte
+}
+
+// newKeyExchange creates the key exchange which is selected by the server in InitResponse. Elliptic curve
+// groups have their own finger prints in ServerKeys, otherwise the finger print belongs to a DH group.
+// If privateKey is set the key exchange is restored instead of generating a new key.
+func (r *River) newKeyExchange(fingerPrint int64, clientNonce, serverNonce uint64, privateKey []byte) (keyExchange, error) {
+	keys := r.getServerKeys()
+	if g, err := keys.GetEcdhGroup(fingerPrint); err == nil {
+		switch g.Curve {
+		case CurveX25519:
+			return newX25519KeyExchange(clientNonce, serverNonce, privateKey)
+		default:
+			return nil, _errors.ErrUnsupportedCurve
+		}
+	}
+
+	dhPrime, dhGen, err := getDHGroup(&keys, fingerPrint)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey == nil {
+		// a precomputed pair is used only once, then the pool is refilled in the background
+		if kex := r.dhPool.take(fingerPrint, dhPrime, dhGen); kex != nil {
+			r.WarmDHPool()
+			return kex, nil
+		}
+	}
+	return newDHKeyExchange(dhPrime, dhGen, privateKey)
+}
+
+// getDHGroup returns the validated prime and generator of the DH group of the server keys
+func getDHGroup(keys *river_conn.ServerKeys, fingerPrint int64) (p, g *big.Int, err error) {
+	dhGroup, err := keys.GetDhGroup(fingerPrint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, ok := big.NewInt(0).SetString(dhGroup.Prime, 16)
+	if !ok {
+		return nil, nil, _errors.ErrInvalidDHPrime
+	}
+	g = big.NewInt(int64(dhGroup.Gen))
+	err = checkDHGroup(dhGroup.FingerPrint, dhGroup.Prime, dhGroup.Gen, p, g)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, g, nil
+}
+
+// dhGroupCheck
+type dhGroupCheck struct {
+	prime string
+	gen   int32
+	err   error
+}
+
+// validatedDHGroups caches the result of validating the DH groups by their finger prints,
+// primality tests of 2048 bits numbers are too expensive to be repeated in each handshake
+var (
+	validatedDHGroupsMtx sync.Mutex
+	validatedDHGroups    = make(map[int64]dhGroupCheck)
+)
+
+func checkDHGroup(fingerPrint int64, prime string, gen int32, p, g *big.Int) error {
+	validatedDHGroupsMtx.Lock()
+	defer validatedDHGroupsMtx.Unlock()
+	c, ok := validatedDHGroups[fingerPrint]
+	if ok && c.prime == prime && c.gen == gen {
+		return c.err
+	}
+	c = dhGroupCheck{
+		prime: prime,
+		gen:   gen,
+		err:   utils.CheckDHGroup(p, g),
+	}
+	validatedDHGroups[fingerPrint] = c
+	return c.err
+}
+
+// dhKeyExchange
+// Finite-field Diffie-Hellman over the groups listed in ServerKeys.DHGroups
+type dhKeyExchange struct {
+	p *big.Int
+	g *big.Int
+	x *big.Int
+	y *big.Int
+}
+
+func newDHKeyExchange(p, g *big.Int, privateKey []byte) (kex *dhKeyExchange, err error) {
+	kex = &dhKeyExchange{
+		p: p,
+		g: g,
+	}
+	if privateKey != nil {
+		kex.x = big.NewInt(0).SetBytes(privateKey)
+		if kex.x.Sign() == 0 || kex.x.Cmp(p) >= 0 {
+			return nil, _errors.ErrInvalidPrivateKey
+		}
+	} else {
+		// x should be in (0, p)
+		for kex.x == nil || kex.x.Sign() == 0 {
+			kex.x, err = rand.Int(rand.Reader, p)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// y = g ^ x mod p
+	kex.y = big.NewInt(0).Exp(g, kex.x, p)
+	err = utils.CheckDHPublicKey(p, kex.y)
+	if err != nil {
+		return nil, err
+	}
+	return kex, nil
+}
+
+func (kex *dhKeyExchange) PublicKey() []byte {
+	return kex.y.Bytes()
+}
+
+func (kex *dhKeyExchange) PrivateKey() []byte {
+	return kex.x.Bytes()
+}
+
+func (kex *dhKeyExchange) ComputeKey(serverPubKey []byte) ([]byte, error) {
+	y := big.NewInt(0).SetBytes(serverPubKey)
+	err := utils.CheckDHPublicKey(kex.p, y)
+	if err != nil {
+		return nil, err
+	}
+
+	// The auth key is g^ab left padded to 256 bytes, since big.Int drops the leading zeros and
+	// about 1 in 256 handshakes would yield a key which disagrees with the server
+	return utils.FixedBytes(big.NewInt(0).Exp(y, kex.x, kex.p), authKeySize)
+}
+
+// x25519KeyExchange
+// The shared secret of X25519 is only 32 bytes, so the auth key is expanded from it by
+// HKDF-SHA512 salted with the handshake nonces
+type x25519KeyExchange struct {
+	privateKey  []byte
+	publicKey   []byte
+	clientNonce uint64
+	serverNonce uint64
+}
+
+func newX25519KeyExchange(clientNonce, serverNonce uint64, privateKey []byte) (*x25519KeyExchange, error) {
+	kex := &x25519KeyExchange{
+		privateKey:  make([]byte, curve25519.ScalarSize),
+		clientNonce: clientNonce,
+		serverNonce: serverNonce,
+	}
+	if privateKey != nil {
+		if len(privateKey) != curve25519.ScalarSize {
+			return nil, _errors.ErrInvalidPrivateKey
+		}
+		copy(kex.privateKey, privateKey)
+	} else if _, err := io.ReadFull(rand.Reader, kex.privateKey); err != nil {
+		return nil, err
+	}
+	publicKey, err := curve25519.X25519(kex.privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	kex.publicKey = publicKey
+	return kex, nil
+}
+
+func (kex *x25519KeyExchange) PublicKey() []byte {
+	return kex.publicKey
+}
+
+func (kex *x25519KeyExchange) PrivateKey() []byte {
+	return kex.privateKey
+}
+
+func (kex *x25519KeyExchange) ComputeKey(serverPubKey []byte) ([]byte, error) {
+	// X25519 returns error for low order points which yield all zero secret
+	secret, err := curve25519.X25519(kex.privateKey, serverPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	binary.LittleEndian.PutUint64(salt, kex.clientNonce)
+	binary.LittleEndian.PutUint64(salt[8:], kex.serverNonce)
+	authKey := make([]byte, authKeySize)
+	_, err = io.ReadFull(hkdf.New(sha512.New, secret, salt, []byte("river auth key")), authKey)
+	if err != nil {
+		return nil, err
+	}
+	return authKey, nil
+}
@@ -0,0 +1,87 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/stub"
+)
+
+// TestDHComputeKeyPadding computes the auth keys g^ab which are less than 2^2040, they are left padded
+// to 256 bytes as the server does
+func TestDHComputeKeyPadding(t *testing.T) {
+	p, _ := big.NewInt(0).SetString(stub.MODP2048, 16)
+	g := big.NewInt(2)
+	tests := []struct {
+		name       string
+		privateKey []byte
+		serverKey  *big.Int
+		// key is g^ab without the leading zeros, zeros is the number of them
+		key   *big.Int
+		zeros int
+	}{
+		// the private key 1 leaves the public key of the server as it is
+		{"2^2031+5", []byte{1}, big.NewInt(0).Add(big.NewInt(0).Lsh(big.NewInt(1), 2031), big.NewInt(5)),
+			big.NewInt(0).Add(big.NewInt(0).Lsh(big.NewInt(1), 2031), big.NewInt(5)), 2},
+		// (2^1019)^2 = 2^2038
+		{"(2^1019)^2", []byte{2}, big.NewInt(0).Lsh(big.NewInt(1), 1019), big.NewInt(0).Lsh(big.NewInt(1), 2038), 1},
+		{"3^3", []byte{3}, big.NewInt(3), big.NewInt(27), 255},
+	}
+	for _, tt := range tests {
+		kex, err := newDHKeyExchange(p, g, tt.privateKey)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		key, err := kex.ComputeKey(tt.serverKey.Bytes())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		expected := append(make([]byte, tt.zeros), tt.key.Bytes()...)
+		if len(expected) != authKeySize || !bytes.Equal(key, expected) {
+			t.Fatalf("%s: the auth key is %x...", tt.name, key[:tt.zeros+1])
+		}
+	}
+}
+
+// TestCheckSecretHash checks the secret hash of an auth key which has a leading zero byte against the
+// hash which is computed here by SHA-256
+func TestCheckSecretHash(t *testing.T) {
+	authKey := make([]byte, authKeySize)
+	authKey[1] = 0x40
+	secretNonce := []byte("0123456789abcdef")
+
+	authKeyHash := sha256.Sum256(authKey)
+	secret := append(append(append([]byte{}, secretNonce...), byte(msg.InitAuthCompleted_OK)), authKeyHash[:8]...)
+	secretHash := sha256.Sum256(secret)
+	expected := binary.LittleEndian.Uint64(secretHash[24:32])
+
+	authID, hash := authKeyID(authKey, secretNonce)
+	if authID != int64(binary.LittleEndian.Uint64(authKeyHash[24:32])) {
+		t.Fatalf("auth id %d", authID)
+	}
+	if err := checkSecretHash(expected, hash); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		expected uint64
+		hash     []byte
+	}{
+		{"first bit", expected ^ 1, hash},
+		{"last bit", expected ^ 1<<63, hash},
+		{"short", expected, hash[:7]},
+		{"empty", expected, nil},
+	} {
+		if err := checkSecretHash(tt.expected, tt.hash); err != _errors.ErrSecretNonceMismatch {
+			t.Errorf("%s: %v, expected %v", tt.name, err, _errors.ErrSecretNonceMismatch)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package river
+
+import (
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// Limits of the untrusted input which is received from the server
+const (
+	// MaxFrameSize is the largest frame which Decode accepts
+	MaxFrameSize = 16 << 20
+	// MaxContainerDepth is the number of the levels of the nested containers which Dispatch unpacks
+	MaxContainerDepth = 4
+)
+
+// recoverMalformed turns a panic of parsing the untrusted input into ErrMalformedInput, in the browser a
+// panic would stop every account
+func recoverMalformed(err *error) {
+	if recover() != nil {
+		*err = _errors.ErrMalformedInput
+	}
+}
@@ -0,0 +1,91 @@
+package river
+
+import (
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// testDispatcher keeps the envelopes which are dispatched
+type testDispatcher struct {
+	updates  [][]byte
+	messages []*msg.MessageEnvelope
+}
+
+func (d *testDispatcher) OnUpdate(data []byte) {
+	d.updates = append(d.updates, data)
+}
+
+func (d *testDispatcher) OnMessage(requestID uint64, constructor int64, data []byte) {
+	d.messages = append(d.messages, &msg.MessageEnvelope{RequestID: requestID, Constructor: constructor, Message: data})
+}
+
+// nestedContainers wraps env in n containers
+func nestedContainers(n int, env *msg.MessageEnvelope) *msg.MessageEnvelope {
+	for i := 0; i < n; i++ {
+		data, _ := (&msg.MessageContainer{Length: 1, Envelopes: []*msg.MessageEnvelope{env}}).Marshal()
+		env = &msg.MessageEnvelope{Constructor: msg.C_MessageContainer, Message: data}
+	}
+	return env
+}
+
+func TestDispatchContainerDepth(t *testing.T) {
+	leaf := &msg.MessageEnvelope{Constructor: msg.C_Error, RequestID: 1}
+
+	d := &testDispatcher{}
+	if err := NewRiver("limits").Dispatch(nestedContainers(MaxContainerDepth, leaf), d); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.messages) != 1 || d.messages[0].RequestID != 1 {
+		t.Fatalf("dispatched %v, expected the envelope of the innermost container", d.messages)
+	}
+
+	d = &testDispatcher{}
+	err := NewRiver("limits").Dispatch(nestedContainers(MaxContainerDepth+1, leaf), d)
+	if err != _errors.ErrContainerTooDeep {
+		t.Fatalf("Dispatch returned %v, expected %v", err, _errors.ErrContainerTooDeep)
+	}
+	if len(d.messages) != 0 {
+		t.Fatalf("dispatched %v from the containers which are too deep", d.messages)
+	}
+}
+
+func TestDecodeFrameTooLarge(t *testing.T) {
+	r := NewRiver("limits")
+	if _, err := r.Decode(make([]byte, MaxFrameSize+1)); err != _errors.ErrFrameTooLarge {
+		t.Fatalf("Decode returned %v, expected %v", err, _errors.ErrFrameTooLarge)
+	}
+	// a frame of the limit is parsed, zeros are not a valid ProtoMessage
+	if _, err := r.Decode(make([]byte, MaxFrameSize)); err == _errors.ErrFrameTooLarge {
+		t.Fatal("Decode rejected a frame of MaxFrameSize")
+	}
+}
+
+// panicDispatcher panics on every envelope
+type panicDispatcher struct{}
+
+func (panicDispatcher) OnUpdate([]byte) {
+	panic("update")
+}
+
+func (panicDispatcher) OnMessage(uint64, int64, []byte) {
+	panic("message")
+}
+
+func TestRecoverMalformed(t *testing.T) {
+	// an account which is not loaded has no connection info to look the auth id up
+	r := NewRiver("limits")
+	frame, _ := (&msg.ProtoMessage{AuthID: 1, MessageKey: make([]byte, 32), Payload: make([]byte, 64)}).Marshal()
+	if _, err := r.Decode(frame); err != _errors.ErrMalformedInput {
+		t.Fatalf("Decode returned %v, expected %v", err, _errors.ErrMalformedInput)
+	}
+	// the lock of the keys is released by the panic
+	r.mtx.Lock()
+	r.mtx.Unlock()
+
+	err := r.Dispatch(nestedContainers(1, &msg.MessageEnvelope{Constructor: msg.C_Error}), panicDispatcher{})
+	if err != _errors.ErrMalformedInput {
+		t.Fatalf("Dispatch returned %v, expected %v", err, _errors.ErrMalformedInput)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"sync"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// TestLoadWhileEncoding loads the connection info again while the requests of a cluster are encoded,
+// go test -race reports the connection info which is replaced without r.mtx
+func TestLoadWhileEncoding(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "load")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if _, err := r.EncodeFor(2, &msg.MessageEnvelope{Constructor: msg.C_Error}); err != _errors.ErrNoAuthKey {
+				t.Errorf("encoded without a key: %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := r.Load("{}", s.ServerKeys()); err != _errors.ErrNoAuthKey {
+			t.Fatalf("Load: %v", err)
+		}
+	}
+	wg.Wait()
+}
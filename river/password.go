@@ -0,0 +1,113 @@
+package river
+
+import (
+	"crypto/rand"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/strength"
+	"strconv"
+	"strings"
+)
+
+// clientSaltSize is the number of random bytes which the client appends to the salt1 of the server
+const clientSaltSize = 32
+
+// GenPasswordSettings accepts AccountPassword marshaled and returns AccountUpdatePasswordSettings marshaled.
+// The current password is proved by oldPassword if the account has a password. If newPassword is empty
+// the password is removed, otherwise the new settings get a fresh salt1 and the verifier of newPassword.
+// answers are the answers of AccountPassword.Questions by their ids, all of them must be answered.
+func (r *River) GenPasswordSettings(
+	oldPassword, newPassword []byte, accountPasswordBytes []byte, hint string, answers map[int32]string,
+) (bytes []byte, err error) {
+	ap := &msg.AccountPassword{}
+	err = ap.Unmarshal(accountPasswordBytes)
+	if err != nil {
+		return
+	}
+
+	req := &msg.AccountUpdatePasswordSettings{}
+	if ap.HasPassword {
+		var proof []byte
+		proof, err = r.GenInputPassword(oldPassword, accountPasswordBytes)
+		if err != nil {
+			return
+		}
+		req.Password = &msg.InputPassword{}
+		if err = req.Password.Unmarshal(proof); err != nil {
+			return
+		}
+	}
+
+	if len(newPassword) > 0 {
+		if ap.Algorithm == 0 {
+			ap.Algorithm = msg.C_PasswordAlgorithmVer6A
+		}
+		var algo PasswordAlgorithm
+		algo, err = getPasswordAlgorithm(ap.Algorithm, ap.AlgorithmData)
+		if err != nil {
+			return
+		}
+		algo, err = renewSalt1(algo)
+		if err != nil {
+			return
+		}
+
+		req.Algorithm = ap.Algorithm
+		if req.AlgorithmData, err = algo.Marshal(); err != nil {
+			return
+		}
+		if req.PasswordHash, err = srpVerifier(algo, newPassword); err != nil {
+			return
+		}
+		req.Hint = hint
+
+		for _, q := range ap.Questions {
+			answer, ok := answers[q.ID]
+			if !ok || normalizeAnswer(answer) == "" {
+				return nil, _errors.ErrNoRecoveryAnswer
+			}
+			req.Answers = append(req.Answers, &msg.RecoveryAnswer{
+				QuestionID: q.ID,
+				AnswerHash: HashRecoveryAnswer(algo, q.ID, answer),
+			})
+		}
+	}
+
+	return req.Marshal()
+}
+
+// renewSalt1 appends our own random to the salt1 of the server, so the server could not reuse
+// a salt for the new password
+func renewSalt1(algo PasswordAlgorithm) (PasswordAlgorithm, error) {
+	salt1, _ := algo.Salts()
+	newSalt1 := make([]byte, len(salt1), len(salt1)+clientSaltSize)
+	copy(newSalt1, salt1)
+	newSalt1 = newSalt1[:len(salt1)+clientSaltSize]
+	if _, err := rand.Read(newSalt1[len(salt1):]); err != nil {
+		return nil, err
+	}
+	return algo.WithSalt1(newSalt1), nil
+}
+
+// HashRecoveryAnswer derives the hash of the answer by the password algorithm. The answers are compared
+// case insensitive and regardless of the spaces, and the question id separates the answers of the
+// questions from each other and from the password.
+func HashRecoveryAnswer(algo PasswordAlgorithm, questionID int32, answer string) []byte {
+	return algo.X([]byte("recovery:" + strconv.FormatInt(int64(questionID), 10) + ":" + normalizeAnswer(answer)))
+}
+
+func normalizeAnswer(answer string) string {
+	return strings.ToLower(strings.Join(strings.Fields(answer), " "))
+}
+
+// PasswordStrength estimates the strength of the password, the details of the account are guessable
+// so they are penalized in the password
+func (r *River) PasswordStrength(password string) strength.Result {
+	var userInputs []string
+	r.mtx.RLock()
+	if r.ConnInfo != nil {
+		userInputs = append(userInputs, r.ConnInfo.Username, r.ConnInfo.FirstName, r.ConnInfo.LastName, r.ConnInfo.Phone)
+	}
+	r.mtx.RUnlock()
+	return strength.Estimate(password, userInputs...)
+}
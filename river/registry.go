@@ -0,0 +1,65 @@
+package river
+
+import (
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"sync"
+)
+
+// Registry
+// Keeps one River instance per account handle, so a single wasm instance could
+// be signed in to several accounts at once
+type Registry struct {
+	mtx      sync.RWMutex
+	accounts map[string]*River
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		accounts: make(map[string]*River),
+	}
+}
+
+// Get returns the River bound to the handle
+func (reg *Registry) Get(handle string) (*River, error) {
+	reg.mtx.RLock()
+	r, ok := reg.accounts[handle]
+	reg.mtx.RUnlock()
+	if !ok {
+		return nil, _errors.ErrAccountNotFound
+	}
+	return r, nil
+}
+
+// GetOrCreate returns the River bound to the handle and creates a new one if there is none
+func (reg *Registry) GetOrCreate(handle string) *River {
+	reg.mtx.Lock()
+	defer reg.mtx.Unlock()
+	r, ok := reg.accounts[handle]
+	if !ok {
+		r = NewRiver(handle)
+		reg.accounts[handle] = r
+	}
+	return r
+}
+
+// Remove drops the account, its keys and state from memory, and stops its background work
+func (reg *Registry) Remove(handle string) {
+	reg.mtx.Lock()
+	r, ok := reg.accounts[handle]
+	delete(reg.accounts, handle)
+	reg.mtx.Unlock()
+	if ok {
+		r.Close()
+	}
+}
+
+// Handles returns the list of loaded accounts
+func (reg *Registry) Handles() []string {
+	reg.mtx.RLock()
+	defer reg.mtx.RUnlock()
+	handles := make([]string, 0, len(reg.accounts))
+	for h := range reg.accounts {
+		handles = append(handles, h)
+	}
+	return handles
+}
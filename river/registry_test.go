@@ -0,0 +1,57 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// TestRegistryRemove checks that a removed account stops filling its DH pool and does not ask for
+// the rotation of its temporary key
+func TestRegistryRemove(t *testing.T) {
+	s := newTestStub(t)
+	reg := NewRegistry()
+	r := reg.GetOrCreate("remove")
+	if err := r.Load("{}", s.ServerKeys()); err != _errors.ErrNoAuthKey {
+		t.Fatal(err)
+	}
+	// the pool is never full, so it is filled until the account is removed
+	r.SetDHPoolSize(1 << 20)
+	now := r.ConnInfo.Now()
+	r.tempKeys.active = &tempKey{authID: 1, createdAt: now, expiresAt: now + 10}
+	rotated := make(chan struct{}, 1)
+	r.ScheduleTempKeyRotation(func() { rotated <- struct{}{} })
+	scheduled := r.rotation.timer
+
+	reg.Remove("remove")
+	if scheduled.Stop() {
+		t.Fatal("the rotation of the removed account is not stopped")
+	}
+	if _, err := reg.Get("remove"); err != _errors.ErrAccountNotFound {
+		t.Fatalf("Get returned %v, expected %v", err, _errors.ErrAccountNotFound)
+	}
+	waitDHPool(t, r)
+	r.WarmDHPool()
+	r.dhPool.mtx.Lock()
+	filling, pairs := r.dhPool.filling, len(r.dhPool.pairs)
+	r.dhPool.mtx.Unlock()
+	if filling || pairs != 0 {
+		t.Fatalf("the pool of the removed account is filling %v with the pairs of %d groups", filling, pairs)
+	}
+
+	r.ScheduleTempKeyRotation(func() { rotated <- struct{}{} })
+	r.rotation.mtx.Lock()
+	timer := r.rotation.timer
+	r.rotation.mtx.Unlock()
+	if timer != nil {
+		t.Fatal("the rotation of the removed account is scheduled again")
+	}
+	select {
+	case <-rotated:
+		t.Fatal("the removed account asked for the rotation")
+	default:
+	}
+}
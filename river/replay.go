@@ -0,0 +1,103 @@
+package river
+
+import (
+	"strconv"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// replayHandle is the handle of the replaying instance, so it never saves over the connection info of an account
+const replayHandle = "river.replay"
+
+// ReplayResult
+// Frames are the indices in the capture, TimeSyncs are the inbound frames after which NeedsTimeSync is set
+type ReplayResult struct {
+	Inbound   int
+	Outbound  int
+	Errors    []ReplayError
+	TimeSyncs []int
+}
+
+// ReplayError
+type ReplayError struct {
+	Frame int
+	Err   error
+}
+
+// Replay feeds the inbound frames of the capture to Decode and Dispatch in their order. The local clock
+// of the replay is the time of each frame, hence a capture is replayed the same way every time. The
+// outbound frames only register their SystemGetServerTime requests, as encode does. The auth keys of
+// the capture are added to the keys of connInfo, which could be empty, e.g. for the redacted captures.
+func Replay(f *CaptureFile, connInfo string, d Dispatcher) (*ReplayResult, error) {
+	if connInfo == "" {
+		connInfo = "{}"
+	}
+	conn, err := river_conn.NewRiverConnection(replayHandle, connInfo)
+	if err != nil {
+		return nil, err
+	}
+	// the keys of the capture are set as clusters which no account has, they are only looked up by their auth ids
+	for idx, k := range f.Keys {
+		authID, err := strconv.ParseInt(k.AuthID, 10, 64)
+		if err != nil || authID == 0 || len(k.AuthKey) != 256 {
+			return nil, _errors.ErrInvalidCapture
+		}
+		var authKey [256]byte
+		copy(authKey[:], k.AuthKey)
+		conn.SetClusterKey(-int32(idx+1), authID, authKey)
+	}
+
+	var now int64
+	conn.SetLocalClock(func() int64 { return now })
+	r := NewRiver(replayHandle)
+	r.ConnInfo = conn
+	r.authID = conn.AuthID
+	if conn.AuthID != 0 {
+		r.authKey = conn.AuthKey[:]
+	}
+
+	res := &ReplayResult{}
+	for idx, frame := range f.Frames {
+		now = frame.Time
+		switch frame.Direction {
+		case CaptureOutbound:
+			res.Outbound++
+			r.replayOutbound(frame.Frame)
+		case CaptureInbound:
+			res.Inbound++
+			env, err := r.Decode(frame.Frame)
+			if err == nil && env == nil {
+				err = _errors.ErrInvalidCapture
+			}
+			if r.NeedsTimeSync() {
+				res.TimeSyncs = append(res.TimeSyncs, idx)
+			}
+			if err == nil {
+				err = r.Dispatch(env, d)
+			}
+			if err != nil {
+				res.Errors = append(res.Errors, ReplayError{Frame: idx, Err: err})
+			}
+		default:
+			return nil, _errors.ErrInvalidCapture
+		}
+	}
+	return res, nil
+}
+
+// replayOutbound registers the SystemGetServerTime request of the frame, which is never encrypted
+func (r *River) replayOutbound(frame []byte) {
+	pm := msg.ProtoMessage{}
+	if err := pm.Unmarshal(frame); err != nil || pm.AuthID != 0 {
+		return
+	}
+	env := msg.MessageEnvelope{}
+	if err := env.Unmarshal(pm.Payload); err != nil {
+		return
+	}
+	if env.Constructor == msg.C_SystemGetServerTime {
+		r.timeRequests.add(env.RequestID, r.localTime())
+	}
+}
@@ -0,0 +1,146 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"fmt"
+	"testing"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// bindHandler replies to AuthBindTempKey by an Error which Items is BOUND if its inner message is
+// sealed by the permanent key of r, the other requests are answered by an Error which Items are ECHO
+func bindHandler(r *River) func(int64, *msg.MessageEnvelope) *msg.MessageEnvelope {
+	reply := func(items string) *msg.MessageEnvelope {
+		data, _ := (&msg.Error{Code: "E00", Items: items}).Marshal()
+		return &msg.MessageEnvelope{Constructor: msg.C_Error, Message: data}
+	}
+	return func(authID int64, req *msg.MessageEnvelope) *msg.MessageEnvelope {
+		if req.Constructor == msg.C_SystemGetServerTime {
+			data, _ := (&msg.SystemServerTime{Timestamp: r.ConnInfo.Now()}).Marshal()
+			return &msg.MessageEnvelope{Constructor: msg.C_SystemServerTime, Message: data}
+		}
+		if req.Constructor != msg.C_AuthBindTempKey {
+			return reply("ECHO")
+		}
+		x := msg.AuthBindTempKey{}
+		pm := msg.ProtoMessage{}
+		if x.Unmarshal(req.Message) != nil || pm.Unmarshal(x.EncryptedMessage) != nil || pm.AuthID != x.PermAuthID {
+			return reply("MALFORMED")
+		}
+		plain, err := utils.Decrypt(r.ConnInfo.AuthKey[:], pm.MessageKey, pm.Payload)
+		payload := msg.ProtoEncryptedPayload{}
+		if err != nil || payload.Unmarshal(plain) != nil || payload.Envelope == nil {
+			return reply("NOT_SEALED")
+		}
+		inner := msg.AuthBindTempKeyInner{}
+		if payload.Envelope.Constructor != msg.C_AuthBindTempKeyInner || inner.Unmarshal(payload.Envelope.Message) != nil ||
+			inner.TempAuthID != authID || inner.PermAuthID != x.PermAuthID {
+			return reply("INNER")
+		}
+		return reply("BOUND")
+	}
+}
+
+// TestCaptureReplay records the binding of a temporary key and the messages which are encrypted by it,
+// then replays the capture and checks the dispatched messages and the recorded frames
+func TestCaptureReplay(t *testing.T) {
+	s := newTestStub(t)
+	r := s.newRiver(t, "replay")
+	s.HandleFunc(bindHandler(r))
+	c := s.dial(t, r)
+	if err := c.auth(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.authTemp(2); err != nil {
+		t.Fatal(err)
+	}
+
+	r.StartCapture(0)
+	requestID := utils.RandomUint64()
+	frame, err := r.BindTempKey(requestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.send(requestID, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := msg.Error{}
+	if err = e.Unmarshal(res.Message); err != nil || e.Items != "BOUND" {
+		t.Fatalf("AuthBindTempKey is answered by %v %q", err, e.Items)
+	}
+	if err = r.TempKeyBound(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.expect(msg.C_SystemGetServerTime, nil, msg.C_SystemServerTime); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.expect(msg.C_Error, nil, msg.C_Error); err != nil {
+		t.Fatal(err)
+	}
+	updates, _ := (&msg.UpdateContainer{Length: 1, Updates: []*msg.UpdateEnvelope{{UpdateID: 9}}, MaxUpdateID: 9}).Marshal()
+	if err = s.Push(r.tempKeys.active.authID, &msg.MessageEnvelope{Constructor: msg.C_UpdateContainer, Message: updates}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.receive(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.StopCapture(CapturePlain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenCapture(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the inner message of AuthBindTempKey is sent inside its request, so it is not a frame
+	var directions []string
+	for _, frame := range f.Frames {
+		directions = append(directions, frame.Direction)
+	}
+	expectedDirections := []string{CaptureOutbound, CaptureInbound, CaptureOutbound, CaptureInbound, CaptureOutbound,
+		CaptureInbound, CaptureInbound}
+	if fmt.Sprint(directions) != fmt.Sprint(expectedDirections) {
+		t.Fatalf("the directions of the frames are %v, expected %v", directions, expectedDirections)
+	}
+
+	d := &testDispatcher{}
+	result, err := Replay(f, "", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Inbound != 4 || result.Outbound != 3 || len(result.Errors) != 0 {
+		t.Fatalf("replayed %+v", result)
+	}
+	expected := []struct {
+		constructor int64
+		items       string
+	}{
+		{msg.C_Error, "BOUND"},
+		{msg.C_SystemServerTime, ""},
+		{msg.C_Error, "ECHO"},
+	}
+	if len(d.messages) != len(expected) {
+		t.Fatalf("dispatched %d messages, expected %d", len(d.messages), len(expected))
+	}
+	for i, m := range d.messages {
+		if m.Constructor != expected[i].constructor {
+			t.Errorf("message %d is %s, expected %s", i, msg.ConstructorName(m.Constructor), msg.ConstructorName(expected[i].constructor))
+			continue
+		}
+		if m.Constructor == msg.C_Error {
+			e := msg.Error{}
+			if err = e.Unmarshal(m.Message); err != nil || e.Items != expected[i].items {
+				t.Errorf("message %d is the Error %q, expected %q", i, e.Items, expected[i].items)
+			}
+		}
+	}
+	if len(d.updates) != 1 {
+		t.Fatalf("dispatched %d updates, expected 1", len(d.updates))
+	}
+}
@@ -3,64 +3,136 @@ package river
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/binary"
-	"fmt"
 	river_conn "git.ronaksoft.com/river/web-wasm/connection"
 	_errors "git.ronaksoft.com/river/web-wasm/errors"
 	"git.ronaksoft.com/river/web-wasm/msg"
 	"git.ronaksoft.com/river/web-wasm/utils"
-	"github.com/monnand/dhkx"
 	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Callback func(time int64)
 
 type River struct {
-	ConnInfo     *river_conn.RiverConnection
-	authID       int64
-	authKey      []byte
-	messageSeq   int64
-	serverKeys   river_conn.ServerKeys
-	dh           *dhkx.DHGroup
-	clientDhKey  *dhkx.DHKey
-	internalAuth *msg.InitCompleteAuthInternal
+	handle     string
+	ConnInfo   *river_conn.RiverConnection
+	mtx        sync.RWMutex // protects the auth keys and the server keys
+	authID     int64
+	authKey    []byte
+	messageSeq int64
+	sessionID  int64
+	serverSalt int64
+	updateID   int64
+	serverKeys river_conn.ServerKeys
+	hsMtx      sync.Mutex
+	handshakes map[int64]*handshake
+	tempKeys   tempKeys
+	dhPool     dhPool
+	rotation   tempKeyRotation
+	// timeRequests and timeSyncNeeded keep the clock in sync with the server
+	timeRequests   timeRequests
+	timeSyncNeeded int32
+	// srpProofs are the expected M2 of the password checks by their SrpID
+	srpMtx    sync.Mutex
+	srpProofs map[int64][]byte
+	// capture records the frames while it is started
+	capMtx  sync.Mutex
+	capture *capture
+}
+
+// NewRiver creates the SDK instance of a single account
+func NewRiver(handle string) *River {
+	return &River{
+		handle:     handle,
+		sessionID:  utils.RandomInt63(),
+		serverSalt: 234242, // TODO:: ServerSalt ?
+		handshakes: make(map[int64]*handshake),
+		srpProofs:  make(map[int64][]byte),
+		dhPool: dhPool{
+			size:  DefaultDHPoolSize,
+			pairs: make(map[int64][]*dhKeyExchange),
+		},
+	}
+}
+
+// Handle returns the account handle this instance is bound to
+func (r *River) Handle() string {
+	return r.handle
+}
+
+// Close stops the background work of the account, the filling of the DH pool and the scheduled
+// rotation of the temporary key, it is called when the account is removed
+func (r *River) Close() {
+	r.closeDHPool()
+	r.stopTempKeyRotation()
 }
 
 func (r *River) Load(connInfo, serverKeys string) (err error) {
-	err = r.serverKeys.UnmarshalJSON([]byte(serverKeys))
+	keys := river_conn.ServerKeys{}
+	err = keys.LoadServerKeys([]byte(serverKeys))
 	if err != nil {
 		return
 	}
+	conn, err := river_conn.NewRiverConnection(r.handle, connInfo)
 
-	r.ConnInfo, err = river_conn.NewRiverConnection(connInfo)
+	// Decode and EncodeFor read the connection info under the lock
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.serverKeys = keys
+	r.ConnInfo = conn
 	if err != nil {
 		return _errors.ErrNoAuthKey
 	}
+	r.restoreSession()
 
 	if r.ConnInfo.AuthID == 0 {
 		return _errors.ErrNoAuthKey
 	}
-
-	fmt.Println(fmt.Sprintf("%+v %+v", r.serverKeys, r.ConnInfo))
-
-	for _, d := range r.serverKeys.PublicKeys {
-		fmt.Println(fmt.Sprintf("%+v", d))
-	}
-	for _, d := range r.serverKeys.DHGroups {
-		fmt.Println(fmt.Sprintf("%+v", d))
-	}
-
 	r.authID = r.ConnInfo.AuthID
 	r.authKey = r.ConnInfo.AuthKey[:]
 	return
 }
 
-func (r *River) AuthStep1(cb Callback) []byte {
+// getServerKeys returns the server keys which are loaded, Load replaces their lists rather than
+// changing them, so the copy is a snapshot
+func (r *River) getServerKeys() river_conn.ServerKeys {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.serverKeys
+}
+
+// AuthStep1 starts the handshake with the cluster, the resulting auth key is stored
+// for that cluster when AuthStep3 finishes. Each handshake is kept by the id of its
+// caller, hence several handshakes could be run at once.
+func (r *River) AuthStep1(id int64, clusterID int32, cb Callback) []byte {
+	return r.authStep1(id, clusterID, 0, cb)
+}
+
+func (r *River) authStep1(id int64, clusterID int32, tempTTL int32, cb Callback) []byte {
 	/* Start Progress */
 	cb(0)
 	/* End progress */
+	hs := &handshake{
+		step:        HandshakeConnecting,
+		clusterID:   clusterID,
+		tempTTL:     tempTTL,
+		clientNonce: utils.RandomUint64(),
+		createdAt:   r.localTime() / 1000,
+		sentAt:      r.localTime(),
+	}
+	r.putHandshake(id, hs)
+	r.clearHandshake(id)
 	req := msg.InitConnect{
-		ClientNonce: utils.RandomUint64(),
+		ClientNonce: hs.clientNonce,
+	}
+	keys := r.getServerKeys()
+	for _, g := range keys.ECDHGroups {
+		req.ECDHFingerPrints = append(req.ECDHFingerPrints, uint64(g.FingerPrint))
 	}
 	bytes, _ := req.Marshal()
 	/* Start Progress */
@@ -69,15 +141,34 @@ func (r *River) AuthStep1(cb Callback) []byte {
 	return bytes
 }
 
-func (r *River) AuthStep2(in []byte, cb Callback) (bytes []byte, err error) {
+func (r *River) AuthStep2(id int64, in []byte, cb Callback) (bytes []byte, err error) {
 	/* Start Progress */
 	cb(12)
 	/* End progress */
+	hs := r.getHandshake(id)
+	if hs == nil {
+		err = _errors.ErrHandshakeStep
+		return
+	}
+	hs.mtx.Lock()
+	defer hs.mtx.Unlock()
+	err = hs.expect(HandshakeConnecting, r.localTime()/1000)
+	if err != nil {
+		return
+	}
+
 	x := msg.InitResponse{}
 	err = x.Unmarshal(in)
 	if err != nil {
 		return
 	}
+	if x.ClientNonce != hs.clientNonce {
+		err = _errors.ErrNonceMismatch
+		return
+	}
+	r.addTimeSample(x.ServerTimestamp, hs.sentAt, r.localTime())
+	hs.serverNonce = x.ServerNonce
+	hs.dhFingerPrint = int64(x.DHGroupFingerPrint)
 
 	req := msg.InitCompleteAuth{
 		ClientNonce:      x.ClientNonce,
@@ -88,53 +179,55 @@ func (r *River) AuthStep2(in []byte, cb Callback) (bytes []byte, err error) {
 		EncryptedPayload: nil,
 	}
 
-	// Generate DH Pub Key
-	dhGroup, err := r.serverKeys.GetDhGroup(int64(x.DHGroupFingerPrint))
-	if err != nil {
-		return
-	}
-
 	/* Start Progress */
 	cb(17)
 	/* End progress */
 
-	dhPrime := big.NewInt(0)
-	dhPrime.SetString(dhGroup.Prime, 16)
+	// Generate DH Pub Key, the server selects either a DH group or an elliptic curve
+	hs.kex, err = r.newKeyExchange(hs.dhFingerPrint, hs.clientNonce, hs.serverNonce, nil)
+	if err != nil {
+		return
+	}
 
 	/* Start Progress */
 	cb(30)
 	/* End progress */
 
-	r.dh = dhkx.CreateGroup(dhPrime, big.NewInt(int64(dhGroup.Gen)))
-	r.clientDhKey, err = r.dh.GeneratePrivateKey(rand.Reader)
-	if err != nil {
-		return
-	}
-
-	req.ClientDHPubKey = r.clientDhKey.Bytes()
+	req.ClientDHPubKey = hs.kex.PublicKey()
 
 	/* Start Progress */
 	cb(35)
 	/* End progress */
-	p, q := utils.SplitPQ(big.NewInt(int64(x.PQ)))
-	if p.Cmp(q) < 0 {
-		req.P = p.Uint64()
-		req.Q = q.Uint64()
-	} else {
-		req.P = q.Uint64()
-		req.Q = p.Uint64()
+	ctx, cancel := r.withCancel(hs, splitPQTimeout)
+	defer cancel()
+	lastProgress := int64(35)
+	p, q, err := utils.SplitPQContext(ctx, big.NewInt(0).SetUint64(x.PQ), 0, func(iterations, maxIterations uint64) {
+		if pr := 35 + int64(10*iterations/maxIterations); pr > lastProgress {
+			lastProgress = pr
+			cb(pr)
+		}
+		// let the event loop run, so the handshake could be cancelled
+		time.Sleep(time.Millisecond)
+	})
+	if err != nil {
+		return
 	}
+	req.P = p.Uint64()
+	req.Q = q.Uint64()
 
 	/* Start Progress */
 	cb(45)
 	/* End progress */
-	r.internalAuth = &msg.InitCompleteAuthInternal{}
-	r.internalAuth.SecretNonce = []byte(utils.RandomID(16))
+	internalAuth := &msg.InitCompleteAuthInternal{}
+	internalAuth.SecretNonce = []byte(utils.RandomID(16))
+	internalAuth.ExpiresIn = hs.tempTTL
+	hs.secretNonce = internalAuth.SecretNonce
 
 	/* Start Progress */
 	cb(50)
 	/* End progress */
-	serverPubKey, err := r.serverKeys.GetPublicKey(int64(x.RSAPubKeyFingerPrint))
+	keys := r.getServerKeys()
+	serverPubKey, err := keys.GetPublicKey(int64(x.RSAPubKeyFingerPrint))
 	if err != nil {
 		return
 	}
@@ -149,12 +242,21 @@ func (r *River) AuthStep2(in []byte, cb Callback) (bytes []byte, err error) {
 	/* Start Progress */
 	cb(55)
 	/* End progress */
-	decrypted, err := r.internalAuth.Marshal()
+	decrypted, err := internalAuth.Marshal()
 	if err != nil {
 		return
 	}
 
-	encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, &rsaPublicKey, decrypted)
+	// The padding is bound to the public key entry, so the server knows it by the finger print
+	var encrypted []byte
+	switch serverPubKey.Padding {
+	case river_conn.PaddingOAEPSHA256:
+		encrypted, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &rsaPublicKey, decrypted, nil)
+	case river_conn.PaddingPKCS1v15:
+		encrypted, err = rsa.EncryptPKCS1v15(rand.Reader, &rsaPublicKey, decrypted)
+	default:
+		err = _errors.ErrUnsupportedPadding
+	}
 	if err != nil {
 		return
 	}
@@ -165,25 +267,43 @@ func (r *River) AuthStep2(in []byte, cb Callback) (bytes []byte, err error) {
 	req.EncryptedPayload = encrypted
 
 	bytes, err = req.Marshal()
+	if err != nil {
+		return
+	}
+
+	hs.request = bytes
+	hs.step = HandshakeCompleting
+	r.saveHandshake(id, hs)
 	return
 }
 
-func (r *River) AuthStep3(in []byte, cb Callback) (bytes []byte, err error) {
+func (r *River) AuthStep3(id int64, in []byte, cb Callback) (bytes []byte, err error) {
+	hs := r.getHandshake(id)
+	if hs == nil {
+		err = _errors.ErrHandshakeStep
+		return
+	}
+	hs.mtx.Lock()
+	defer hs.mtx.Unlock()
+	err = hs.expect(HandshakeCompleting, r.localTime()/1000)
+	if err != nil {
+		return
+	}
+
 	x := msg.InitAuthCompleted{}
 	err = x.Unmarshal(in)
 	if err != nil {
 		return
 	}
+	if x.ClientNonce != hs.clientNonce || x.ServerNonce != hs.serverNonce {
+		err = _errors.ErrNonceMismatch
+		return
+	}
 
 	switch x.Status {
 	case msg.InitAuthCompleted_OK:
-		var (
-			serverDhKey *dhkx.DHKey
-			authKeyHash []byte
-			secretHash  []byte
-		)
-
-		serverDhKey, err = r.dh.ComputeKey(dhkx.NewPublicKey(x.ServerDHPubKey), r.clientDhKey)
+		var serverDhKey []byte
+		serverDhKey, err = hs.kex.ComputeKey(x.ServerDHPubKey)
 		if err != nil {
 			return
 		}
@@ -192,30 +312,19 @@ func (r *River) AuthStep3(in []byte, cb Callback) (bytes []byte, err error) {
 		cb(70)
 		/* End progress */
 
-		copy(r.ConnInfo.AuthKey[:], serverDhKey.Bytes())
-		authKeyHash, err = utils.Sha256(r.ConnInfo.AuthKey[:])
-		if err != nil {
+		var authKey [256]byte
+		if len(serverDhKey) != len(authKey) {
+			err = _errors.ErrInvalidAuthKey
 			return
 		}
-
-		r.ConnInfo.AuthID = int64(binary.LittleEndian.Uint64(authKeyHash[24:32]))
+		copy(authKey[:], serverDhKey)
+		authID, secretHash := authKeyID(authKey[:], hs.secretNonce)
 
 		/* Start Progress */
 		cb(80)
 		/* End progress */
 
-		var secret []byte
-		secret = append(secret, r.internalAuth.SecretNonce...)
-		secret = append(secret, byte(msg.InitAuthCompleted_OK))
-		secret = append(secret, authKeyHash[:8]...)
-		secretHash, err = utils.Sha256(secret)
-		if err != nil {
-			return
-		}
-
-		if x.SecretHash != binary.LittleEndian.Uint64(secretHash[24:32]) {
-			//fmt.Println(x.SecretHash, binary.LittleEndian.Uint64(secretHash[24:32]))
-			err = _errors.ErrSecretNonceMismatch
+		if err = checkSecretHash(x.SecretHash, secretHash); err != nil {
 			return
 		}
 
@@ -223,9 +332,22 @@ func (r *River) AuthStep3(in []byte, cb Callback) (bytes []byte, err error) {
 		cb(90)
 		/* End progress */
 
-		r.ConnInfo.Save()
-		r.authKey = r.ConnInfo.AuthKey[:]
-		r.authID = r.ConnInfo.AuthID
+		r.mtx.Lock()
+		if hs.tempTTL > 0 {
+			// Temporary keys are never persisted, they must be bound to the permanent key by BindTempKey
+			now := r.ConnInfo.Now()
+			r.tempKeys.setPending(authID, authKey, now, now+int64(hs.tempTTL))
+		} else {
+			r.ConnInfo.SetClusterKey(hs.clusterID, authID, authKey)
+			r.ConnInfo.Save()
+		}
+		if hs.tempTTL == 0 && hs.clusterID == river_conn.DefaultClusterID {
+			r.authKey = r.ConnInfo.AuthKey[:]
+			r.authID = r.ConnInfo.AuthID
+		}
+		r.mtx.Unlock()
+		hs.step = HandshakeDone
+		r.removeHandshake(id)
 
 		/* Start Progress */
 		cb(100)
@@ -234,13 +356,24 @@ func (r *River) AuthStep3(in []byte, cb Callback) (bytes []byte, err error) {
 	case msg.InitAuthCompleted_RETRY:
 		// TODO:: Retry with new DHKey
 	case msg.InitAuthCompleted_FAIL:
+		r.removeHandshake(id)
 		err = _errors.ErrAuthFailed
 		return
 	}
 	return
 }
 
+// Decode decodes the frame which is received from the server, the frames larger than MaxFrameSize are rejected
 func (r *River) Decode(in []byte) (out *msg.MessageEnvelope, err error) {
+	if len(in) > MaxFrameSize {
+		return nil, _errors.ErrFrameTooLarge
+	}
+	defer func() {
+		if recover() != nil {
+			out, err = nil, _errors.ErrMalformedInput
+		}
+	}()
+	r.recordFrame(CaptureInbound, in)
 	res := msg.ProtoMessage{}
 	err = res.Unmarshal(in)
 	if err != nil {
@@ -253,11 +386,20 @@ func (r *River) Decode(in []byte) (out *msg.MessageEnvelope, err error) {
 		if err != nil {
 			return
 		}
+		if out.Constructor == msg.C_SystemServerTime {
+			r.onServerTime(out)
+		}
+
+		return
+	}
 
+	authKey, err := r.keyByAuthID(res.AuthID)
+	if err != nil {
 		return
 	}
+	r.recordKey(res.AuthID, authKey)
 
-	decryptedBytes, err := utils.Decrypt(r.authKey, res.MessageKey, res.Payload)
+	decryptedBytes, err := utils.Decrypt(authKey, res.MessageKey, res.Payload)
 	if err != nil {
 		//js.Global().Call("fnDecryptError")
 		return
@@ -270,17 +412,92 @@ func (r *River) Decode(in []byte) (out *msg.MessageEnvelope, err error) {
 	}
 
 	out = receivedEncryptedPayload.Envelope
+	if out != nil && out.Constructor == msg.C_SystemServerTime {
+		// the reply of the sync is not checked against the clock which it syncs
+		r.onServerTime(out)
+	} else {
+		r.checkClockSkew(receivedEncryptedPayload.MessageID)
+	}
 	return
 }
 
+// keyByAuthID returns the auth key which a received frame is encrypted with, the lock is released even if
+// the lookup panics
+func (r *River) keyByAuthID(authID int64) ([]byte, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if authID == r.authID {
+		return r.authKey, nil
+	}
+	if authKey := r.tempKeyByAuthID(authID); authKey != nil {
+		return authKey, nil
+	}
+	return r.ConnInfo.GetKeyByAuthID(authID)
+}
+
+// Encode encodes the envelope with the auth key of the default cluster
 func (r *River) Encode(in *msg.MessageEnvelope) (bytes []byte, err error) {
-	protoMessage := new(msg.ProtoMessage)
-	protoMessage.AuthID = r.authID
-	protoMessage.MessageKey = make([]byte, 32)
+	return r.EncodeFor(river_conn.DefaultClusterID, in)
+}
+
+// EncodeFor encodes the envelope with the auth key which is negotiated with the cluster, the handshake
+// is encoded before the cluster has any key
+func (r *River) EncodeFor(clusterID int32, in *msg.MessageEnvelope) (bytes []byte, err error) {
+	if unencrypted(in.Constructor) {
+		return r.encode(0, nil, in)
+	}
+	r.mtx.RLock()
+	authID, authKey := r.authID, r.authKey
+	if clusterID != river_conn.DefaultClusterID {
+		authID, authKey, err = r.ConnInfo.GetClusterKey(clusterID)
+	} else if tk, tkErr := r.activeTempKey(); tkErr != nil {
+		err = tkErr
+	} else if tk != nil {
+		authID, authKey = tk.authID, tk.authKey[:]
+	}
+	r.mtx.RUnlock()
+	if err != nil {
+		return
+	}
+
+	return r.encode(authID, authKey, in)
+}
 
-	if r.authID == 0 || in.Constructor == msg.C_SystemGetServerTime ||
-		in.Constructor == msg.C_SystemGetInfo || in.Constructor == msg.C_SystemGetSalts ||
-		in.Constructor == msg.C_InitConnect || in.Constructor == msg.C_InitCompleteAuth {
+// encode seals the envelope as an outbound frame, which is recorded if the capture is started
+func (r *River) encode(authID int64, authKey []byte, in *msg.MessageEnvelope) (bytes []byte, err error) {
+	if in.Constructor == msg.C_SystemGetServerTime {
+		r.timeRequests.add(in.RequestID, r.localTime())
+	}
+	protoMessage, err := r.seal(authID, authKey, in)
+	if err != nil {
+		return
+	}
+	bytes, err = protoMessage.Marshal()
+	if err != nil {
+		return
+	}
+	r.recordFrame(CaptureOutbound, bytes)
+	r.recordKey(protoMessage.AuthID, authKey)
+	return
+}
+
+// unencrypted reports whether the constructor is sent without encryption, e.g. the handshake
+func unencrypted(constructor int64) bool {
+	switch constructor {
+	case msg.C_SystemGetServerTime, msg.C_SystemGetInfo, msg.C_SystemGetSalts,
+		msg.C_InitConnect, msg.C_InitCompleteAuth:
+		return true
+	}
+	return false
+}
+
+// seal encrypts the envelope by the auth key, the messages which are sent before the handshake are not
+// encrypted. The sealed message is not recorded, e.g. the inner message of AuthBindTempKey is no frame.
+func (r *River) seal(authID int64, authKey []byte, in *msg.MessageEnvelope) (protoMessage *msg.ProtoMessage, err error) {
+	protoMessage = new(msg.ProtoMessage)
+	protoMessage.AuthID = authID
+	protoMessage.MessageKey = make([]byte, 32)
+	if authID == 0 || unencrypted(in.Constructor) {
 		protoMessage.AuthID = 0
 		protoMessage.Payload, err = in.Marshal()
 		if err != nil {
@@ -289,94 +506,222 @@ func (r *River) Encode(in *msg.MessageEnvelope) (bytes []byte, err error) {
 	} else {
 		var unencryptedBytes []byte
 
-		protoMessage.AuthID = r.authID
-		r.messageSeq++
+		protoMessage.AuthID = authID
+		messageSeq := atomic.AddInt64(&r.messageSeq, 1)
 		encryptedPayload := msg.ProtoEncryptedPayload{
-			ServerSalt: 234242, // TODO:: ServerSalt ?
+			ServerSalt: atomic.LoadInt64(&r.serverSalt),
+			SessionID:  r.sessionID,
 			Envelope:   in,
 		}
-		encryptedPayload.MessageID = uint64(r.ConnInfo.Now()<<32 | r.messageSeq)
+		encryptedPayload.MessageID = uint64(r.ConnInfo.Now()<<32 | messageSeq)
 		unencryptedBytes, err = encryptedPayload.Marshal()
 		if err != nil {
 			return
 		}
 
-		encryptedPayloadBytes, _ := utils.Encrypt(r.authKey, unencryptedBytes)
-		messageKey := utils.GenerateMessageKey(r.authKey, unencryptedBytes)
+		encryptedPayloadBytes, _ := utils.Encrypt(authKey, unencryptedBytes)
+		messageKey := utils.GenerateMessageKey(authKey, unencryptedBytes)
 		copy(protoMessage.MessageKey, messageKey)
 		protoMessage.Payload = encryptedPayloadBytes
 	}
+	return
+}
 
-	bytes, err = protoMessage.Marshal()
+// ExportKey exports the auth key of the cluster to be imported for another cluster
+// which accepts the same keys
+func (r *River) ExportKey(clusterID int32) ([]byte, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.ConnInfo.ExportClusterKey(clusterID)
+}
+
+// ImportKey binds an exported auth key to the cluster
+func (r *River) ImportKey(clusterID int32, data []byte) (err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	err = r.ConnInfo.ImportClusterKey(clusterID, data)
 	if err != nil {
 		return
 	}
-
+	if clusterID == river_conn.DefaultClusterID {
+		r.authKey = r.ConnInfo.AuthKey[:]
+		r.authID = r.ConnInfo.AuthID
+	}
+	r.ConnInfo.Save()
 	return
 }
 
-// GenSrpHash generates a hash to be used in AuthCheckPassword and other related apis
-func (r *River) GenSrpHash(password []byte, algorithm int64, algorithmData []byte) (bytes []byte, err error) {
-	switch algorithm {
-	case msg.C_PasswordAlgorithmVer6A:
-		algo := &msg.PasswordAlgorithmVer6A{}
-		err = algo.Unmarshal(algorithmData)
+// SetServerSalt sets the salt which is used for the upcoming encrypted messages
+func (r *River) SetServerSalt(salt int64) {
+	atomic.StoreInt64(&r.serverSalt, salt)
+	if r.ConnInfo == nil {
+		return
+	}
+	r.mtx.Lock()
+	r.ConnInfo.Salts = []river_conn.ServerSalt{{Salt: salt, ValidSince: r.ConnInfo.Now()}}
+	r.ConnInfo.SessionID = r.sessionID
+	if r.ConnInfo.AuthID != 0 {
+		r.ConnInfo.Save()
+	}
+	r.mtx.Unlock()
+}
 
-		if err != nil {
+// restoreSession continues the persisted session with the latest salt which is valid by now, r.mtx must be held
+func (r *River) restoreSession() {
+	if r.ConnInfo.SessionID != 0 {
+		r.sessionID = r.ConnInfo.SessionID
+	}
+	now := r.ConnInfo.Now()
+	validSince := int64(0)
+	for _, s := range r.ConnInfo.Salts {
+		if s.ValidSince <= now && s.ValidSince >= validSince {
+			validSince = s.ValidSince
+			atomic.StoreInt64(&r.serverSalt, s.Salt)
+		}
+	}
+}
+
+// SetUpdateID keeps track of the latest update received by this account
+func (r *River) SetUpdateID(updateID int64) {
+	for {
+		current := atomic.LoadInt64(&r.updateID)
+		if updateID <= current || atomic.CompareAndSwapInt64(&r.updateID, current, updateID) {
 			return
 		}
+	}
+}
 
-		p := big.NewInt(0).SetBytes(algo.P)
-		x := big.NewInt(0).SetBytes(utils.PH2(password, algo.Salt1, algo.Salt2))
-		v := big.NewInt(0).Exp(big.NewInt(int64(algo.G)), x, p)
-		bytes = v.Bytes()
-		return
-	default:
+// UpdateID returns the latest update id received by this account
+func (r *River) UpdateID() int64 {
+	return atomic.LoadInt64(&r.updateID)
+}
+
+// GenSrpHash generates a hash to be used in AuthCheckPassword and other related apis
+func (r *River) GenSrpHash(password []byte, algorithm int64, algorithmData []byte) (bytes []byte, err error) {
+	algo, err := getPasswordAlgorithm(algorithm, algorithmData)
+	if err != nil {
 		return
 	}
+
+	return srpVerifier(algo, password)
 }
 
 // GenInputPassword  accepts AccountPassword marshaled as argument and return InputPassword marshaled
 func (r *River) GenInputPassword(password []byte, accountPasswordBytes []byte) (bytes []byte, err error) {
 	ap := &msg.AccountPassword{}
 	err = ap.Unmarshal(accountPasswordBytes)
+	if err != nil {
+		return
+	}
 
-	algo := &msg.PasswordAlgorithmVer6A{}
-	err = algo.Unmarshal(ap.AlgorithmData)
+	// the old servers leave the algorithm unset, they only support PasswordAlgorithmVer6A
+	if ap.Algorithm == 0 {
+		ap.Algorithm = msg.C_PasswordAlgorithmVer6A
+	}
+	algo, err := getPasswordAlgorithm(ap.Algorithm, ap.AlgorithmData)
 	if err != nil {
 		return
 	}
 
-	p := big.NewInt(0).SetBytes(algo.P)
-	g := big.NewInt(0).SetInt64(int64(algo.G))
+	var (
+		inputPassword *msg.InputPassword
+		m2            []byte
+	)
+	// A of a random a is out of range once in 2^1980, the check is left to genInputPassword
+	for {
+		a := make([]byte, utils.SrpByteSize)
+		if _, err = rand.Read(a); err != nil {
+			return
+		}
+		inputPassword, m2, err = genInputPassword(algo, ap, password, a)
+		if err != _errors.ErrInvalidSrpA {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+	r.addSrpProof(ap.SrpID, m2)
+
+	bytes, err = inputPassword.Marshal()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// genInputPassword returns InputPassword and the expected M2 of the server by the secret a of the client,
+// ErrInvalidSrpA means another a must be tried
+func genInputPassword(algo PasswordAlgorithm, ap *msg.AccountPassword, password, secret []byte) (
+	inputPassword *msg.InputPassword, m2 []byte, err error,
+) {
+	p, g, err := checkedGroup(algo)
+	if err != nil {
+		return
+	}
+	gb := big.NewInt(0).SetBytes(ap.SrpB)
+	if !utils.CheckSrpPublic(p, gb) {
+		err = _errors.ErrInvalidSrpB
+		return
+	}
+	salt1, salt2 := algo.Salts()
 	k := big.NewInt(0).SetBytes(utils.K(p, g))
 
-	x := big.NewInt(0).SetBytes(utils.PH2(password, algo.Salt1, algo.Salt2))
-	v := big.NewInt(0).Exp(g, x, p)
-	a := big.NewInt(0).SetBytes(ap.RandomData)
+	// a is never taken from RandomData, a server which chose it could guess the password offline from M1
+	// without the verifier
+	a := big.NewInt(0).SetBytes(secret)
 	ga := big.NewInt(0).Exp(g, a, p)
-	gb := big.NewInt(0).SetBytes(ap.SrpB)
+	if !utils.CheckSrpPublic(p, ga) {
+		err = _errors.ErrInvalidSrpA
+		return
+	}
 	u := big.NewInt(0).SetBytes(utils.U(ga, gb))
+	if u.Sign() == 0 {
+		err = _errors.ErrInvalidSrpU
+		return
+	}
+
+	x := big.NewInt(0).SetBytes(algo.X(password))
+	v := big.NewInt(0).Exp(g, x, p)
 	kv := big.NewInt(0).Mod(big.NewInt(0).Mul(k, v), p)
 	t := big.NewInt(0).Mod(big.NewInt(0).Sub(gb, kv), p)
 	if t.Sign() < 0 {
 		t.Add(t, p)
 	}
 	sa := big.NewInt(0).Exp(t, big.NewInt(0).Add(a, big.NewInt(0).Mul(u, x)), p)
-	m1 := utils.M(p, g, algo.Salt1, algo.Salt2, ga, gb, sa)
+	m1 := utils.M(p, g, salt1, salt2, ga, gb, sa)
 
-	inputPassword := &msg.InputPassword{
+	inputPassword = &msg.InputPassword{
 		SrpID: ap.SrpID,
 		A:     utils.Pad(ga),
 		M1:    m1,
 	}
+	return inputPassword, utils.M2(ga, m1, sa), nil
+}
 
-	bytes, err = inputPassword.Marshal()
-	if err != nil {
-		return
-	}
+// authKeyID returns the auth id of the auth key and the last 8 bytes of the secret hash, which the server
+// proves it has the same auth key by
+func authKeyID(authKey, secretNonce []byte) (authID int64, secretHash []byte) {
+	authKeyHash, _ := utils.Sha256(authKey)
+	authID = int64(binary.LittleEndian.Uint64(authKeyHash[24:32]))
+
+	secret := make([]byte, 0, len(secretNonce)+9)
+	secret = append(secret, secretNonce...)
+	secret = append(secret, byte(msg.InitAuthCompleted_OK))
+	secret = append(secret, authKeyHash[:8]...)
+	h, _ := utils.Sha256(secret)
+	return authID, h[24:32]
+}
 
-	return
+// checkSecretHash compares the secret hash of InitAuthCompleted with ours in constant time
+func checkSecretHash(expected uint64, secretHash []byte) error {
+	expectedHash := make([]byte, 8)
+	binary.LittleEndian.PutUint64(expectedHash, expected)
+	if subtle.ConstantTimeCompare(expectedHash, secretHash) != 1 {
+		return _errors.ErrSecretNonceMismatch
+	}
+	return nil
 }
 
 func TeamHeader(teamID, teamAccessHash string) []*msg.KeyValue {
@@ -0,0 +1,196 @@
+package river
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// SelfTestVersion is the version of the known answers, it is bumped whenever a vector is added or changed,
+// so the server could tell which set it is checked against
+const SelfTestVersion = 3
+
+// SelfTestVectors
+// Known answers of the crypto core which are shared with the server in JSON. The ids and the big numbers
+// are decimal strings, the bytes are base64.
+type SelfTestVectors struct {
+	Version        int
+	Ciphers        []CipherVector
+	SplitPQ        []SplitPQVector
+	AuthKeys       []AuthKeyVector
+	SrpHashes      []SrpHashVector
+	InputPasswords []InputPasswordVector
+}
+
+// CipherVector
+// utils.GenerateMessageKey, utils.Encrypt and utils.Decrypt of Plain by AuthKey
+type CipherVector struct {
+	AuthKey    []byte
+	Plain      []byte
+	MessageKey []byte
+	Encrypted  []byte
+}
+
+// SplitPQVector
+type SplitPQVector struct {
+	PQ string
+	P  string
+	Q  string
+}
+
+// AuthKeyVector
+// The auth id of AuthKey and the SecretHash of InitAuthCompleted which the server proves the key by
+type AuthKeyVector struct {
+	AuthKey     []byte
+	SecretNonce []byte
+	AuthID      string
+	SecretHash  string
+}
+
+// SrpHashVector
+// The verifier which GenSrpHash returns
+type SrpHashVector struct {
+	Algorithm     int64
+	AlgorithmData []byte
+	Password      []byte
+	Hash          []byte
+}
+
+// InputPasswordVector
+// InputPassword which GenInputPassword returns for AccountPassword when its secret a is Random, and the M2
+// which the server must reply
+type InputPasswordVector struct {
+	Password        []byte
+	AccountPassword []byte
+	Random          []byte
+	InputPassword   []byte
+	M2              []byte
+}
+
+// SelfTestError names the vector which does not match its known answer
+type SelfTestError struct {
+	Vector string
+	Index  int
+}
+
+func (e *SelfTestError) Error() string {
+	return "self test failed at " + e.Vector + " " + strconv.Itoa(e.Index)
+}
+
+// SelfTestVectorsJSON returns the known answers which SelfTest checks
+func SelfTestVectorsJSON() string {
+	return selfTestVectorsJSON
+}
+
+// SelfTest checks the crypto core against its known answers, so a miscompiled build is caught before it
+// encrypts any message. The fast test takes a few milliseconds, the full one checks the slow vectors too,
+// which validate the SRP groups and factorize PQ by big numbers.
+func SelfTest(full bool) error {
+	return selfTest([]byte(selfTestVectorsJSON), full)
+}
+
+func selfTest(vectors []byte, full bool) error {
+	v := SelfTestVectors{}
+	if err := v.UnmarshalJSON(vectors); err != nil {
+		return err
+	}
+	if v.Version != SelfTestVersion {
+		return &SelfTestError{Vector: "Version"}
+	}
+	for idx, x := range v.Ciphers {
+		if !x.check() {
+			return &SelfTestError{Vector: "Ciphers", Index: idx}
+		}
+	}
+	for idx, x := range v.SplitPQ {
+		if !x.check(full) {
+			return &SelfTestError{Vector: "SplitPQ", Index: idx}
+		}
+	}
+	for idx, x := range v.AuthKeys {
+		authID, secretHash := authKeyID(x.AuthKey, x.SecretNonce)
+		if strconv.FormatInt(authID, 10) != x.AuthID || len(secretHash) != 8 ||
+			strconv.FormatUint(binary.LittleEndian.Uint64(secretHash), 10) != x.SecretHash {
+			return &SelfTestError{Vector: "AuthKeys", Index: idx}
+		}
+	}
+	if !full {
+		return nil
+	}
+	for idx, x := range v.SrpHashes {
+		algo, err := getPasswordAlgorithm(x.Algorithm, x.AlgorithmData)
+		if err != nil {
+			return &SelfTestError{Vector: "SrpHashes", Index: idx}
+		}
+		hash, err := srpVerifier(algo, x.Password)
+		if err != nil || !bytes.Equal(hash, x.Hash) {
+			return &SelfTestError{Vector: "SrpHashes", Index: idx}
+		}
+	}
+	for idx, x := range v.InputPasswords {
+		if !x.check() {
+			return &SelfTestError{Vector: "InputPasswords", Index: idx}
+		}
+	}
+	return nil
+}
+
+func (x CipherVector) check() bool {
+	if len(x.AuthKey) != 256 || !bytes.Equal(utils.GenerateMessageKey(x.AuthKey, x.Plain), x.MessageKey) {
+		return false
+	}
+	// Encrypt could seal the plain in place
+	encrypted, err := utils.Encrypt(x.AuthKey, append([]byte(nil), x.Plain...))
+	if err != nil || !bytes.Equal(encrypted, x.Encrypted) {
+		return false
+	}
+	plain, err := utils.Decrypt(x.AuthKey, x.MessageKey, x.Encrypted)
+	if err != nil || !bytes.Equal(plain, x.Plain) {
+		return false
+	}
+	// the tag must be checked, otherwise any tampered message is accepted
+	tampered := append([]byte(nil), x.Encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = utils.Decrypt(x.AuthKey, x.MessageKey, tampered)
+	return err != nil
+}
+
+func (x SplitPQVector) check(full bool) bool {
+	pq, ok1 := big.NewInt(0).SetString(x.PQ, 10)
+	p, ok2 := big.NewInt(0).SetString(x.P, 10)
+	q, ok3 := big.NewInt(0).SetString(x.Q, 10)
+	if !ok1 || !ok2 || !ok3 {
+		return false
+	}
+	p1, q1, err := utils.SplitPQContext(context.Background(), pq, 0, nil)
+	if err != nil || p1.Cmp(p) != 0 || q1.Cmp(q) != 0 {
+		return false
+	}
+	if full {
+		p1, q1 = utils.SplitPQ(pq)
+		return p1.Cmp(p) == 0 && q1.Cmp(q) == 0
+	}
+	return true
+}
+
+func (x InputPasswordVector) check() bool {
+	ap := &msg.AccountPassword{}
+	if err := ap.Unmarshal(x.AccountPassword); err != nil {
+		return false
+	}
+	algo, err := getPasswordAlgorithm(ap.Algorithm, ap.AlgorithmData)
+	if err != nil {
+		return false
+	}
+	inputPassword, m2, err := genInputPassword(algo, ap, x.Password, x.Random)
+	if err != nil {
+		return false
+	}
+	b, err := inputPassword.Marshal()
+	return err == nil && bytes.Equal(b, x.InputPassword) && bytes.Equal(m2, x.M2)
+}
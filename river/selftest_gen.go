@@ -0,0 +1,49 @@
+//go:build ignore
+// +build ignore
+
+// selftest_gen writes selftest_vectors.go from the known answers which are shared with the server:
+//
+//	go run selftest_gen.go testdata/selftest_v3.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run selftest_gen.go testdata/selftest_v<version>.json")
+	}
+	data, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	var v struct{ Version int }
+	if err = json.Unmarshal(data, &v); err != nil {
+		log.Fatal(err)
+	}
+	if filepath.Base(os.Args[1]) != fmt.Sprintf("selftest_v%d.json", v.Version) {
+		log.Fatalf("%s has the vectors of version %d", os.Args[1], v.Version)
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.IndexByte(data, '`') >= 0 {
+		log.Fatal("the vectors have a back quote")
+	}
+	var b strings.Builder
+	b.WriteString("// Code generated by selftest_gen.go; DO NOT EDIT.\n\n")
+	b.WriteString("package river\n\n")
+	fmt.Fprintf(&b, "// selfTestVectorsJSON are the known answers of SelfTestVersion in %s, the server\n",
+		filepath.ToSlash(os.Args[1]))
+	b.WriteString("// checks its own implementation against the same file\n")
+	fmt.Fprintf(&b, "const selfTestVectorsJSON = `%s`\n", data)
+	if err = ioutil.WriteFile("selftest_vectors.go", []byte(b.String()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,294 @@
+package river
+
+import (
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+)
+
+// MarshalJSON implements json.Marshaler
+func (v SelfTestVectors) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Version")
+	w.Int64(int64(v.Version))
+	w.Field("Ciphers")
+	w.Array(v.Ciphers == nil, len(v.Ciphers), func(i int) { v.Ciphers[i].writeJSON(&w) })
+	w.Field("SplitPQ")
+	w.Array(v.SplitPQ == nil, len(v.SplitPQ), func(i int) { v.SplitPQ[i].writeJSON(&w) })
+	w.Field("AuthKeys")
+	w.Array(v.AuthKeys == nil, len(v.AuthKeys), func(i int) { v.AuthKeys[i].writeJSON(&w) })
+	w.Field("SrpHashes")
+	w.Array(v.SrpHashes == nil, len(v.SrpHashes), func(i int) { v.SrpHashes[i].writeJSON(&w) })
+	w.Field("InputPasswords")
+	w.Array(v.InputPasswords == nil, len(v.InputPasswords), func(i int) { v.InputPasswords[i].writeJSON(&w) })
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *SelfTestVectors) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Version":
+			v.Version = l.Int()
+		case "Ciphers":
+			v.Ciphers = []CipherVector{}
+			l.Array(func() {
+				x := CipherVector{}
+				x.readJSON(l)
+				v.Ciphers = append(v.Ciphers, x)
+			})
+		case "SplitPQ":
+			v.SplitPQ = []SplitPQVector{}
+			l.Array(func() {
+				x := SplitPQVector{}
+				x.readJSON(l)
+				v.SplitPQ = append(v.SplitPQ, x)
+			})
+		case "AuthKeys":
+			v.AuthKeys = []AuthKeyVector{}
+			l.Array(func() {
+				x := AuthKeyVector{}
+				x.readJSON(l)
+				v.AuthKeys = append(v.AuthKeys, x)
+			})
+		case "SrpHashes":
+			v.SrpHashes = []SrpHashVector{}
+			l.Array(func() {
+				x := SrpHashVector{}
+				x.readJSON(l)
+				v.SrpHashes = append(v.SrpHashes, x)
+			})
+		case "InputPasswords":
+			v.InputPasswords = []InputPasswordVector{}
+			l.Array(func() {
+				x := InputPasswordVector{}
+				x.readJSON(l)
+				v.InputPasswords = append(v.InputPasswords, x)
+			})
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v CipherVector) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CipherVector) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v CipherVector) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("AuthKey")
+	w.Base64(v.AuthKey)
+	w.Field("Plain")
+	w.Base64(v.Plain)
+	w.Field("MessageKey")
+	w.Base64(v.MessageKey)
+	w.Field("Encrypted")
+	w.Base64(v.Encrypted)
+	w.ObjectEnd()
+}
+
+func (v *CipherVector) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "AuthKey":
+			v.AuthKey = l.Bytes()
+		case "Plain":
+			v.Plain = l.Bytes()
+		case "MessageKey":
+			v.MessageKey = l.Bytes()
+		case "Encrypted":
+			v.Encrypted = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v SplitPQVector) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *SplitPQVector) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v SplitPQVector) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("PQ")
+	w.String(v.PQ)
+	w.Field("P")
+	w.String(v.P)
+	w.Field("Q")
+	w.String(v.Q)
+	w.ObjectEnd()
+}
+
+func (v *SplitPQVector) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "PQ":
+			v.PQ = l.String()
+		case "P":
+			v.P = l.String()
+		case "Q":
+			v.Q = l.String()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v AuthKeyVector) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *AuthKeyVector) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v AuthKeyVector) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("AuthKey")
+	w.Base64(v.AuthKey)
+	w.Field("SecretNonce")
+	w.Base64(v.SecretNonce)
+	w.Field("AuthID")
+	w.String(v.AuthID)
+	w.Field("SecretHash")
+	w.String(v.SecretHash)
+	w.ObjectEnd()
+}
+
+func (v *AuthKeyVector) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "AuthKey":
+			v.AuthKey = l.Bytes()
+		case "SecretNonce":
+			v.SecretNonce = l.Bytes()
+		case "AuthID":
+			v.AuthID = l.String()
+		case "SecretHash":
+			v.SecretHash = l.String()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v SrpHashVector) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *SrpHashVector) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v SrpHashVector) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Algorithm")
+	w.Int64(v.Algorithm)
+	w.Field("AlgorithmData")
+	w.Base64(v.AlgorithmData)
+	w.Field("Password")
+	w.Base64(v.Password)
+	w.Field("Hash")
+	w.Base64(v.Hash)
+	w.ObjectEnd()
+}
+
+func (v *SrpHashVector) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Algorithm":
+			v.Algorithm = l.Int64()
+		case "AlgorithmData":
+			v.AlgorithmData = l.Bytes()
+		case "Password":
+			v.Password = l.Bytes()
+		case "Hash":
+			v.Hash = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
+
+// MarshalJSON implements json.Marshaler
+func (v InputPasswordVector) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *InputPasswordVector) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v InputPasswordVector) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("Password")
+	w.Base64(v.Password)
+	w.Field("AccountPassword")
+	w.Base64(v.AccountPassword)
+	w.Field("Random")
+	w.Base64(v.Random)
+	w.Field("InputPassword")
+	w.Base64(v.InputPassword)
+	w.Field("M2")
+	w.Base64(v.M2)
+	w.ObjectEnd()
+}
+
+func (v *InputPasswordVector) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "Password":
+			v.Password = l.Bytes()
+		case "AccountPassword":
+			v.AccountPassword = l.Bytes()
+		case "Random":
+			v.Random = l.Bytes()
+		case "InputPassword":
+			v.InputPassword = l.Bytes()
+		case "M2":
+			v.M2 = l.Bytes()
+		default:
+			l.Skip()
+		}
+	})
+}
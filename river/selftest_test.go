@@ -0,0 +1,225 @@
+package river
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"golang.org/x/crypto/argon2"
+)
+
+// selfTestFile is the file of the known answers which are shared with the server
+var selfTestFile = filepath.Join("testdata", fmt.Sprintf("selftest_v%d.json", SelfTestVersion))
+
+func readSelfTestFile(t *testing.T) []byte {
+	data, err := ioutil.ReadFile(selfTestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.TrimSpace(data)
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SelfTest(true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSelfTestVectorsFile checks selftest_vectors.go is written from the shared file by selftest_gen.go
+func TestSelfTestVectorsFile(t *testing.T) {
+	if data := readSelfTestFile(t); string(data) != selfTestVectorsJSON {
+		t.Fatalf("selftest_vectors.go differs from %s, run go run selftest_gen.go %s", selfTestFile,
+			filepath.ToSlash(selfTestFile))
+	}
+}
+
+// TestSelfTestTampered changes a byte of each kind of vector, the self test must name the vector
+func TestSelfTestTampered(t *testing.T) {
+	v := SelfTestVectors{}
+	if err := v.UnmarshalJSON(readSelfTestFile(t)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		vector string
+		full   bool
+		tamper func(v *SelfTestVectors)
+	}{
+		{"Ciphers", false, func(v *SelfTestVectors) { v.Ciphers[1].Encrypted[0] ^= 1 }},
+		{"SplitPQ", false, func(v *SelfTestVectors) { v.SplitPQ[1].P, v.SplitPQ[1].Q = v.SplitPQ[1].Q, v.SplitPQ[1].P }},
+		{"AuthKeys", false, func(v *SelfTestVectors) { v.AuthKeys[1].SecretNonce[0] ^= 1 }},
+		{"SrpHashes", true, func(v *SelfTestVectors) { v.SrpHashes[1].Password[0] ^= 1 }},
+		{"InputPasswords", true, func(v *SelfTestVectors) { v.InputPasswords[0].M2[0] ^= 1 }},
+	}
+	for _, tt := range tests {
+		x := SelfTestVectors{}
+		if err := x.UnmarshalJSON(readSelfTestFile(t)); err != nil {
+			t.Fatal(err)
+		}
+		tt.tamper(&x)
+		data, _ := x.MarshalJSON()
+		err, ok := selfTest(data, tt.full).(*SelfTestError)
+		if !ok || err.Vector != tt.vector {
+			t.Errorf("tampered %s is reported as %v", tt.vector, err)
+		}
+	}
+	v.Version++
+	data, _ := v.MarshalJSON()
+	if err, ok := selfTest(data, false).(*SelfTestError); !ok || err.Vector != "Version" {
+		t.Errorf("another version is reported as %v", err)
+	}
+}
+
+// referenceVectors is the shared file decoded by encoding/json
+type referenceVectors struct {
+	Ciphers []struct {
+		AuthKey, Plain, MessageKey, Encrypted []byte
+	}
+	SplitPQ []struct {
+		PQ, P, Q string
+	}
+	AuthKeys []struct {
+		AuthKey, SecretNonce []byte
+		AuthID, SecretHash   string
+	}
+	SrpHashes []struct {
+		Algorithm                     int64
+		AlgorithmData, Password, Hash []byte
+	}
+	InputPasswords []struct {
+		Password, AccountPassword, Random, InputPassword, M2 []byte
+	}
+}
+
+func sha256Of(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func sha512Of(data ...[]byte) []byte {
+	h := sha512.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func saltedSha256(data, salt []byte) []byte {
+	return sha256Of(salt, data, salt)
+}
+
+func pad256(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, 256))
+}
+
+// referenceX returns x of the password by the algorithm, the group and the salts
+func referenceX(t *testing.T, algorithm int64, algorithmData, password []byte) (x, p, g *big.Int, salt1, salt2 []byte) {
+	switch algorithm {
+	case msg.C_PasswordAlgorithmVer6A:
+		a := msg.PasswordAlgorithmVer6A{}
+		if err := a.Unmarshal(algorithmData); err != nil {
+			t.Fatal(err)
+		}
+		ph1 := saltedSha256(saltedSha256(password, a.Salt1), a.Salt2)
+		x = big.NewInt(0).SetBytes(saltedSha256(saltedSha256(ph1, a.Salt1), a.Salt2))
+		return x, big.NewInt(0).SetBytes(a.P), big.NewInt(int64(a.G)), a.Salt1, a.Salt2
+	case msg.C_PasswordAlgorithmVer6AArgon2id:
+		a := msg.PasswordAlgorithmVer6AArgon2Id{}
+		if err := a.Unmarshal(algorithmData); err != nil {
+			t.Fatal(err)
+		}
+		ph1 := saltedSha256(saltedSha256(password, a.Salt1), a.Salt2)
+		stretched := argon2.IDKey(ph1, a.Salt1, a.Iterations, a.Memory, uint8(a.Parallelism), 64)
+		x = big.NewInt(0).SetBytes(saltedSha256(stretched, a.Salt2))
+		return x, big.NewInt(0).SetBytes(a.P), big.NewInt(int64(a.G)), a.Salt1, a.Salt2
+	}
+	t.Fatalf("unknown algorithm %d", algorithm)
+	return
+}
+
+// TestSelfTestVectorsReference computes the known answers again by the standard library apart from the
+// crypto core, so a vector which is written by a broken build is caught
+func TestSelfTestVectorsReference(t *testing.T) {
+	v := referenceVectors{}
+	if err := json.Unmarshal(readSelfTestFile(t), &v); err != nil {
+		t.Fatal(err)
+	}
+	for i, x := range v.Ciphers {
+		messageKey := sha512Of(x.AuthKey[100:140], x.Plain)[32:64]
+		aesIV := sha512Of(x.AuthKey[180:220], messageKey)[:12]
+		aesKey := sha512Of(messageKey, x.AuthKey[170:210])[:32]
+		block, _ := aes.NewCipher(aesKey)
+		gcm, _ := cipher.NewGCM(block)
+		if !bytes.Equal(messageKey, x.MessageKey) || !bytes.Equal(gcm.Seal(nil, aesIV, x.Plain, nil), x.Encrypted) {
+			t.Errorf("Ciphers %d", i)
+		}
+	}
+	for i, x := range v.SplitPQ {
+		pq, _ := big.NewInt(0).SetString(x.PQ, 10)
+		p, _ := big.NewInt(0).SetString(x.P, 10)
+		q, _ := big.NewInt(0).SetString(x.Q, 10)
+		if pq == nil || p == nil || q == nil || big.NewInt(0).Mul(p, q).Cmp(pq) != 0 || p.Cmp(q) > 0 ||
+			!p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+			t.Errorf("SplitPQ %d", i)
+		}
+	}
+	for i, x := range v.AuthKeys {
+		authKeyHash := sha256Of(x.AuthKey)
+		authID := int64(binary.LittleEndian.Uint64(authKeyHash[24:32]))
+		secretHash := sha256Of(x.SecretNonce, []byte{byte(msg.InitAuthCompleted_OK)}, authKeyHash[:8])[24:32]
+		if strconv.FormatInt(authID, 10) != x.AuthID ||
+			strconv.FormatUint(binary.LittleEndian.Uint64(secretHash), 10) != x.SecretHash {
+			t.Errorf("AuthKeys %d", i)
+		}
+	}
+	for i, x := range v.SrpHashes {
+		sx, p, g, _, _ := referenceX(t, x.Algorithm, x.AlgorithmData, x.Password)
+		if !bytes.Equal(big.NewInt(0).Exp(g, sx, p).Bytes(), x.Hash) {
+			t.Errorf("SrpHashes %d", i)
+		}
+	}
+	for i, x := range v.InputPasswords {
+		ap := msg.AccountPassword{}
+		if err := ap.Unmarshal(x.AccountPassword); err != nil {
+			t.Fatal(err)
+		}
+		sx, p, g, salt1, salt2 := referenceX(t, ap.Algorithm, ap.AlgorithmData, x.Password)
+		// S = (B - k*g^x)^(a + u*x), the proofs are M1 = H(H(p), H(g), H(s1), H(s2), H(A), H(B), H(S))
+		// and M2 = H(A, M1, H(S))
+		k := big.NewInt(0).SetBytes(sha256Of(pad256(p), pad256(g)))
+		a := big.NewInt(0).SetBytes(x.Random)
+		ga := big.NewInt(0).Exp(g, a, p)
+		gb := big.NewInt(0).SetBytes(ap.SrpB)
+		u := big.NewInt(0).SetBytes(sha256Of(pad256(ga), pad256(gb)))
+		base := big.NewInt(0).Exp(g, sx, p)
+		base.Mul(base, k).Sub(gb, base).Mod(base, p)
+		s := big.NewInt(0).Exp(base, big.NewInt(0).Add(a, big.NewInt(0).Mul(u, sx)), p)
+		m1 := sha256Of(sha256Of(pad256(p)), sha256Of(pad256(g)), sha256Of(salt1), sha256Of(salt2),
+			sha256Of(pad256(ga)), sha256Of(pad256(gb)), sha256Of(pad256(s)))
+		m2 := sha256Of(pad256(ga), m1, sha256Of(pad256(s)))
+		inputPassword := msg.InputPassword{}
+		if err := inputPassword.Unmarshal(x.InputPassword); err != nil {
+			t.Fatal(err)
+		}
+		if inputPassword.SrpID != ap.SrpID || !bytes.Equal(inputPassword.A, pad256(ga)) ||
+			!bytes.Equal(inputPassword.M1, m1) || !bytes.Equal(m2, x.M2) {
+			t.Errorf("InputPasswords %d", i)
+		}
+	}
+}
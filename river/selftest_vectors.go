@@ -0,0 +1,84 @@
+// Code generated by selftest_gen.go; DO NOT EDIT.
+
+package river
+
+// selfTestVectorsJSON are the known answers of SelfTestVersion in testdata/selftest_v3.json, the server
+// checks its own implementation against the same file
+const selfTestVectorsJSON = `{
+  "Version": 3,
+  "Ciphers": [
+    {
+      "AuthKey": "DGqypbz2KInR/BIiNpOO6nYrBPwxoXid3YKSUjoVIodVFX+VSweO1NXNJeNH5fVgzih4vF1z5JkuGHKxkGsR7SvIZNftLW6mvHlA4digFr9katqegJMBJFty4peX6BjWvOtIT5KPyxIkX5mKS/fxDV48+jjrxNvBN8LEUNaHDeg4Ja1k8xDCmOyoPOl2HRH8fWWE0kUmGI74TpZFz0Reqx2TlEKQbcst0FKqatNHlWTY+n2W8BEP54f5boKv/FFXF+DuinNNm2oy5RGj7j5m8jDoKRHiI/7YUYY3Ux2aKUP2j6/wp/JqGJ4yv/9K5SEvCAzl81HnaTZiYby8vKe6+g==",
+      "Plain": "3A==",
+      "MessageKey": "60+xjMkaCwEJLRqOrK7uY1zVNvXaRs3ACZj26tuhroo=",
+      "Encrypted": "l2u+Iou/jvZ8DH6nm4OUweM="
+    },
+    {
+      "AuthKey": "4rTRL3KLJptRRYLqS9+7aTvW+TtPRu4nkr6cSUVz2jtndLdJ5inX1r5PmeeBxQZUb93wBp8eHt0fwvJ/1E6rYL91dFGyHJSQdWpUOiSUXjoeQ7tPtzD0RFjEYONi7x36XDP3pgfF/MVMmkaj1uA2IkI5oUJLYpM/VHxzOga8koJNnWCFqjd7f1/MnakimImbTylxCgHRioF2bNaKv3rRD224vR26CqzV739ADz9VQTBHGJvABl0mbi898HkSqjW7tKSgQwwP6vsknEE71SrGU/G97gOPSIQJSZC1za7loFU5iPtX9WBYiptOXkBOojsAUAukpUdiS3ff/q2efBMgGg==",
+      "Plain": "RjhcO3B6nKP3/la7sn9TCESIlwr5hNiucxkQWIPlAXwWzQSLNXHI+4giMXrIEMcY",
+      "MessageKey": "jafiUsM43d5nBLokelP66l2GJxa24HxO36+wzhp/1uY=",
+      "Encrypted": "MLTtYWLRWs8DRcQLkTz2fLsATEUjIQ7XPwPHc/ZeLd0WVr3EcJ+hzD7EPHTGR/qcQAOJlqRwhERODiuWgo9Wcg=="
+    },
+    {
+      "AuthKey": "CUg8F8VtNxRYJ1HQO3DbLTnwh0S8hIx/7MhdnSy23U17OfXoUGoLQMASfDpD1IByZKXWuh1cjYpTAYvAhuSoGA78HL6xxeY7n/3E4Rxkzqi8miQMt7iyPJL+b7BF0p9fjeQ3lQXX7mN77EAdVXnZajYsIccMfgOes8l+wAo/wJ1kf30FG393nByMnseWUyV/Qf1Kr2vc5BX+TWwNI6JcuQAbnORf8lVEPaMwYArkQPVWo/x3PffB2WZrSmzdjEf+taeL+vVIUjSW5NQKv5NpAaqDopFaleKcBJmnHuD2fycN/hwM6N0GdjuRvSjppfzyKtYyg30g7/eHBLtI+3uC+A==",
+      "Plain": "J/3A4oLz3WquRDXWdhXwFEMdZsZmx0UPsLSHq0VGGIuKUSxhneNwA4pD4klY4T8u9hU0Sqq6wKhOKFz/mkB2kuMzTd5/AZ6L6oFDu0mbipNvFNKTnSTp44aZqD/1kfuH9oOdxWblLvrDB85nvs9BDOvg6D+3S1Cig045oortPTVq9wFyiFf+ui/8hErU/547NHewOXK7gk1tjMbBxrxOUQwA9+r04IeTcbDnrVw0pNjodNy1JpMQkeCaSQEs0URKedLDySlsNzwi1YhDxKz0npPD0ipYGuAmRTPRGzIcRYW/F/5dP7mmWwh342JJkP0NtG9CLLCR1tUpJkjpUI5xXA==",
+      "MessageKey": "ktG2vnSWdUqrQhveOA1BIbiI8FHJ+mKJoV44J4PSVco=",
+      "Encrypted": "CcPeZ0rABxr9+9ZWO/5V1f+jp5XWH362qwkdJjNj5wQ8TfPFb4z2FZs3RDtOaV61E4yz+/zjSBobv3bjDCRB47MgH/sh01CVLEGpjm/CX/4BubrLx2MRVFWzJfNXkR9ZRvc1S+HVO9GjT98HN/NyC90EJw+ZZq2fS8zhaxgByk8/Wn7lNsGqEeWUqgA9ZeXnWEd98H8eRKuSVuhZ63GewLj7ulOCxb9MdstMdcCF71oxscldSVmp8L97azDDk4ztdrsl89hXCx5iCUIZX5UuTK+pe3hkPI3kYmPen4QGIgnhAUk4e0cUmC3++BHiuWALPKvgxq/gfyUW+/OreeFm/txKoKhZJTHp5MGrmgom40o="
+    }
+  ],
+  "SplitPQ": [
+    {
+      "PQ": "1724114033281923457",
+      "P": "1229739323",
+      "Q": "1402015859"
+    },
+    {
+      "PQ": "4611685975477714963",
+      "P": "2147483629",
+      "Q": "2147483647"
+    }
+  ],
+  "AuthKeys": [
+    {
+      "AuthKey": "qeF77RRVm+o0K9Z/lem2rF+eeOXeRae6DVSVGPEsp5SuRnDs2hJc4wTtDfbbBMJigY4kKAhPkTVYkWxsVWsYAr8Hu1+N9+1gr3Pjzee5Kvn4YC//kWknWz2uKtPEk4qw2rDPJ2zoHUUmhGvMvWEUyswxd/pOWH5IEGR2cEFnDY8jmIoex042zs1La6vKxKZi4sEBKQFD2zqNqGra4fHGnSS+HAlcUZMYhBP+krkhOfuHKOO3+lIgcMv0JUUbd63z8zisueB0aNHbIuuTUArIXef9J4fYOzF4OWW7+YAn7HnoBFk3P/fte03lUOozb1QcljdrcrLyw8eudH6SjI1X/Q==",
+      "SecretNonce": "qMfnYgbrBUurPgCFZZ6QACQB3FUx+CF2G1uaa+G2Ul4=",
+      "AuthID": "-119364168448486776",
+      "SecretHash": "16579745648694118560"
+    },
+    {
+      "AuthKey": "rq5lJ82myXVWKPJ3oodyK5vOnp5oD+2XL9JWA0/GwwXNNZz1SMctM2PE9cuu+jix4STDEVKY9368FHxQbM4XWbGQkZtKuv+x2rVA8j0zhhFoO+O3uyqC1IOUdQkxEc1nkn4XniqsXvSs0sfpZOpYrPod74mKmdEthnEuJ+KfSiXugYWgKGWhD+sKS4EMh3/hNZGJRt6dYP0ZirCzNxnmuf4CTNUAC01rdDz1xmF4J4QHFWmfC8de4kE6MoU+hervIfORzf1kNgT94YM4SQ3mCi8XjQB5msBbNJE4qgQ8wEklo9RmVOnkG/lkDkjN4H8snkI+Pic1gjraq6WCkMD0cw==",
+      "SecretNonce": "dXZt1OPcSxH7doCtnqHSwAKsGoh4ZqDTy6oLXuhG+P8=",
+      "AuthID": "-5521808593695495338",
+      "SecretHash": "3556214752241887053"
+    }
+  ],
+  "SrpHashes": [
+    {
+      "Algorithm": 341860043,
+      "AlgorithmData": "CiDj6ArEy7MhAhHrwow8OfqKnXe8m4a4sE44P+9tNRnPihIgtDjdXCnrTb4ZO+AxHNfnIKW3EW7Q5Ko14i6zqq5Evv8YAiKAAv//////////yQ/aoiFowjTExmKLgNwc0SkCTgiKZ8x0Agu+pjsTmyJRSgh5jjQE3e+VGbPNOkMbMCsKbfJfFDdP4TVtbVHCReSFtXZiXn7G9ExC6aY37WsL/1y29Aa37e44a/taiZ+lrp8kEXxLH+ZJKGZR7ORbPcIAfLihY78FmNpINhxV05ppFj+o/STPX4NlXSPco62WHGLzViCFUrue1SkHcJaWbWcMNU5KvJgE8XRsCMoYIXwykF5GLjbOO+OedywYDoYDmyeDouwHoo+1xV3wb0xSyd4ry/aVWBcYOZVJfOqVauUV0iYYmPoFEBVyjlqKrKpo//////////8=",
+      "Password": "Y29ycmVjdCBob3JzZSBiYXR0ZXJ5IHN0YXBsZQ==",
+      "Hash": "iGJmG+9IGEwl9NtW8UU9GLHaAmK4PoUq5Ul7TQ/KawQ9PKafgwbagdnsccIrkYE2IAvdRoqpadAYBp2A8BhuqqLCxgIyDjR44EiOPYvhWm23cfC+M08NR1a+fBXJDXtCxsOe0C5nfjZ+X78iBi70FjNF9ZIadLwQElINrYBdXCuyZ8AMu8FxEKFud9LZKMM8T3LUivtRATvTE1FbA8bYtxfXgBmdR1CkuypmekIbX35wWtEckktEoUe95jOs4v9nFu2m4VoTqE6lDF6wIXBi8wyZAbcRkek9BlHqLtusMPP/r7cdxUJ2Rgc+XfL5JZwx3evedkLWMHc7QQh9fijCUQ=="
+    },
+    {
+      "Algorithm": 341860043,
+      "AlgorithmData": "CiDj6ArEy7MhAhHrwow8OfqKnXe8m4a4sE44P+9tNRnPihIgtDjdXCnrTb4ZO+AxHNfnIKW3EW7Q5Ko14i6zqq5Evv8YAiKAAv//////////yQ/aoiFowjTExmKLgNwc0SkCTgiKZ8x0Agu+pjsTmyJRSgh5jjQE3e+VGbPNOkMbMCsKbfJfFDdP4TVtbVHCReSFtXZiXn7G9ExC6aY37WsL/1y29Aa37e44a/taiZ+lrp8kEXxLH+ZJKGZR7ORbPcIAfLihY78FmNpINhxV05ppFj+o/STPX4NlXSPco62WHGLzViCFUrue1SkHcJaWbWcMNU5KvJgE8XRsCMoYIXwykF5GLjbOO+OedywYDoYDmyeDouwHoo+1xV3wb0xSyd4ry/aVWBcYOZVJfOqVauUV0iYYmPoFEBVyjlqKrKpo//////////8=",
+      "Password": "2LHZhdiyINi52KjZiNixINux27Lbsw==",
+      "Hash": "vuflACw/KqwoOaRXodlpTkO84qGWbmCuc09uPHbfUnkq4Bjdv4Mi+R7MIV2dMiP24xMjzIiexcHC0inHZKRxe8XvG3f0qT2Nj85smzk5lB3HumhUH84hJb8SIkgPD2pRvxo1EINmEltrc6n+aXslcgh2uGgoG6QTWZjQCSAsUtcNm7Rpau/9MPpHmYL5C99ImnUEAaGvzlCDVRY5ELB7sfrYguQkDN05tu9gUUbA/BY7+9rJNNjbMMS6aX/rHI7kYHk2NlsSYVgVwBpT5HXHoBa+OYFEtbzOSodUQB4GF/1rQlPPWrbbF3EBT3CjNn1vL91r0Q51tP1II3WTfInNEg=="
+    },
+    {
+      "Algorithm": 1043673236,
+      "AlgorithmData": "CiDj6ArEy7MhAhHrwow8OfqKnXe8m4a4sE44P+9tNRnPihIgtDjdXCnrTb4ZO+AxHNfnIKW3EW7Q5Ko14i6zqq5Evv8YAiKAAv//////////yQ/aoiFowjTExmKLgNwc0SkCTgiKZ8x0Agu+pjsTmyJRSgh5jjQE3e+VGbPNOkMbMCsKbfJfFDdP4TVtbVHCReSFtXZiXn7G9ExC6aY37WsL/1y29Aa37e44a/taiZ+lrp8kEXxLH+ZJKGZR7ORbPcIAfLihY78FmNpINhxV05ppFj+o/STPX4NlXSPco62WHGLzViCFUrue1SkHcJaWbWcMNU5KvJgE8XRsCMoYIXwykF5GLjbOO+OedywYDoYDmyeDouwHoo+1xV3wb0xSyd4ry/aVWBcYOZVJfOqVauUV0iYYmPoFEBVyjlqKrKpo//////////8oATBAOAE=",
+      "Password": "Y29ycmVjdCBob3JzZSBiYXR0ZXJ5IHN0YXBsZQ==",
+      "Hash": "3wfYTZ3uPmjNsJoJ3z0urg7WLMNOGHMo+YKp/pG4EefJox6AGQ+H3miUbmZvRIQysPwiekrws/Lz63cxgc93o2/RPFqP7+wUhF7HfwfOH8LDr2sPqauyrLrUWsJx08sKI8zBRmrgjPRJpG7IUx03nWggd+8G8LB+2UwYPQRPP+8voQkZD+CvdBpIxj6vfS4SgYjjELcBIE8VB1phnecK4cO4v0i4giRW7vpXEOGFufWiIghUE5c95d3c00/cD3y/LGa8ZnKSHd5qsm67zSr+Tf/RY2GN6FZsqM/DTaMttHKKQwiXmqQgLmhkaDdd+TWJv9qI4r9eXugQsnn8jDj4SA=="
+    }
+  ],
+  "InputPasswords": [
+    {
+      "Password": "Y29ycmVjdCBob3JzZSBiYXR0ZXJ5IHN0YXBsZQ==",
+      "AccountPassword": "CAEYy72BowEiyQIKIOPoCsTLsyECEevCjDw5+oqdd7ybhriwTjg/7201Gc+KEiC0ON1cKetNvhk74DEc1+cgpbcRbtDkqjXiLrOqrkS+/xgCIoAC///////////JD9qiIWjCNMTGYouA3BzRKQJOCIpnzHQCC76mOxObIlFKCHmONATd75UZs806QxswKwpt8l8UN0/hNW1tUcJF5IW1dmJefsb0TELppjftawv/XLb0Brft7jhr+1qJn6WunyQRfEsf5kkoZlHs5Fs9wgB8uKFjvwWY2kg2HFXTmmkWP6j9JM9fg2VdI9yjrZYcYvNWIIVSu57VKQdwlpZtZww1Tkq8mATxdGwIyhghfDKQXkYuNs474553LBgOhgObJ4Oi7Aeij7XFXfBvTFLJ3ivL9pVYFxg5lUl86pVq5RXSJhiY+gUQFXKOWoqsqmj//////////yqAAvApNY2DTQDmQIYDZj2LQxUrgTvTqijarLD7c1Dh3RwEEFB6WXVSiG1uRwnHUS9sNtDbghcf/9ww1B9mhLGUKCMLHg0lMzM7EN/97qKaedX4b6Uqu2QUsuw02I/pXq4kY+iM8EZ5o2G4RnCC9wL4WS4EpOqS/6TOm9ry4+mBAY4rEP0bt1m1PRS1ZjfBVhO8ipto/ynhd4XmOViNJcsvyLnJaAUx/r7NoiSd5JvkDQFY52fVKy7jQ/ysIWfxMPLs7AvmgHTyDf04+VrUjOFX5YtIs3HJZ7Mf99XGJ0M7MdKWjDLZcGw7fNVBSMCP3sZn2fGjMTcpjaBbMomh/G4PGA44wYQ9",
+      "Random": "0zUDd4YlQVVE8MAg5BT93zsMJSxLo6bV9dFwiqkPYBL//Ewmb8mMEhTVS8s0YDfSd9LHVi3i+mxZpOkXSJPsk2DW7JWz0PQ46XwnVc1M1tWmp6pMDTroGFD1M6hteg1jFY0Ams8XG6bexhtB0vyE4KyWOsPY+kMcmSoIE3OdYCNUbemiLguG8pOoPZpPQJ+6g5rKWLXgJIADwIqUzF6jqIM0RgcvHg5uFq+UX/ldNAOZI2NqVnNjJqdOtsZV8ROXlq1AwrOzP+wPuPSHnFE8KdAI6w2R6v+WbGkte0AuoWGQsOknGXDs+gcVKUswVz59hd3XYK+fMVrUjb5mtZhmsg==",
+      "InputPassword": "CMGEPRKAAjpfuIMOC/kP41Ox2bEy+4Rp37Ptq6J5rfYkgMqHCipY+cSJ9LeDX1CtUjruQhVP8epsNDa9q/a9T5CsPFTeDdsFc8urV8C8rPzuImMz+1C/xnU0RoCCl1sbuUFYLSb3shyvHWzO9zkatAW+p9Fbzmv0f6efQqhPWhjY7XgI9cX1AlV7GlLUVwNS2EOkF/ANxPnolBdN+HPuNETsk3VikDFE0A117rL/rqv+0ud718FMpvDf3Q26oTbM46vkOk95W8IYQEOmwqJ8p+TFOP8t3XVDj9HTbkjnIw5wydHlNEFR/PHqVzzdWNSjQkp1LjVRukTvZNMI50XsaUBGVBW6JWUaIDuAGEHQof88AGOUbXs/kVkolRfDjAV+lrs+lQM9hp0A",
+      "M2": "RqruvLOPES3CNokjsyZ29BKYKlHY3r2BIGsdBQI5nsw="
+    }
+  ]
+}`
@@ -0,0 +1,246 @@
+package river
+
+import (
+	"crypto/subtle"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"math/big"
+	"sync"
+)
+
+// Bounds of the Argon2id parameters which are accepted from the server, the memory is in KiB
+const (
+	argon2MaxIterations  = 16
+	argon2MaxMemory      = 256 * 1024
+	argon2MaxParallelism = 16
+)
+
+// PasswordAlgorithm
+// The SRP password algorithm which is selected by AccountPassword.Algorithm
+type PasswordAlgorithm interface {
+	// Group returns the SRP prime and generator
+	Group() (p, g *big.Int)
+	// Salts returns the salts which are used in M1
+	Salts() (salt1, salt2 []byte)
+	// X derives the SRP private key from the password
+	X(password []byte) []byte
+	// WithSalt1 returns a copy of the algorithm with another salt1, it is used for the new passwords
+	WithSalt1(salt1 []byte) PasswordAlgorithm
+	// Marshal returns the algorithm data which is sent to the server
+	Marshal() ([]byte, error)
+}
+
+// PasswordAlgorithmFactory creates the algorithm from its marshaled parameters
+type PasswordAlgorithmFactory func(algorithmData []byte) (PasswordAlgorithm, error)
+
+var (
+	passwordAlgorithmsMtx sync.RWMutex
+	passwordAlgorithms    = map[int64]PasswordAlgorithmFactory{
+		msg.C_PasswordAlgorithmVer6A:         newPasswordAlgorithmVer6A,
+		msg.C_PasswordAlgorithmVer6AArgon2id: newPasswordAlgorithmVer6AArgon2id,
+	}
+)
+
+// RegisterPasswordAlgorithm adds the password algorithm of the constructor, or replaces it
+func RegisterPasswordAlgorithm(constructor int64, factory PasswordAlgorithmFactory) {
+	passwordAlgorithmsMtx.Lock()
+	passwordAlgorithms[constructor] = factory
+	passwordAlgorithmsMtx.Unlock()
+}
+
+// getPasswordAlgorithm returns the password algorithm of the constructor
+func getPasswordAlgorithm(constructor int64, algorithmData []byte) (PasswordAlgorithm, error) {
+	passwordAlgorithmsMtx.RLock()
+	factory, ok := passwordAlgorithms[constructor]
+	passwordAlgorithmsMtx.RUnlock()
+	if !ok {
+		return nil, _errors.ErrUnsupportedPasswordAlgorithm
+	}
+	return factory(algorithmData)
+}
+
+// validatedSrpGroups caches the result of validating the SRP groups, the key is the prime and the generator
+var (
+	validatedSrpGroupsMtx sync.Mutex
+	validatedSrpGroups    = make(map[string]error)
+)
+
+// checkedGroup returns the SRP group of the algorithm if it is valid
+func checkedGroup(algo PasswordAlgorithm) (p, g *big.Int, err error) {
+	p, g = algo.Group()
+	if p == nil || g == nil {
+		return nil, nil, _errors.ErrInvalidSrpPrime
+	}
+	key := string(p.Bytes()) + "/" + g.String()
+	validatedSrpGroupsMtx.Lock()
+	defer validatedSrpGroupsMtx.Unlock()
+	err, ok := validatedSrpGroups[key]
+	if !ok {
+		err = utils.CheckSrpGroup(p, g)
+		validatedSrpGroups[key] = err
+	}
+	return p, g, err
+}
+
+// srpVerifier returns the verifier v = g^x mod p of the password
+func srpVerifier(algo PasswordAlgorithm, password []byte) ([]byte, error) {
+	p, g, err := checkedGroup(algo)
+	if err != nil {
+		return nil, err
+	}
+	x := big.NewInt(0).SetBytes(algo.X(password))
+	return big.NewInt(0).Exp(g, x, p).Bytes(), nil
+}
+
+// maxSrpProofs bounds the proofs which are kept when the server never sends M2
+const maxSrpProofs = 8
+
+// addSrpProof keeps the expected M2 of the password check until it is verified
+func (r *River) addSrpProof(srpID int64, m2 []byte) {
+	r.srpMtx.Lock()
+	if len(r.srpProofs) >= maxSrpProofs {
+		for id := range r.srpProofs {
+			delete(r.srpProofs, id)
+			break
+		}
+	}
+	r.srpProofs[srpID] = m2
+	r.srpMtx.Unlock()
+}
+
+// VerifySrpM2 checks the proof of the server for the password check of srpID, which proves the server
+// knows the verifier. Each proof is verified once.
+func (r *River) VerifySrpM2(srpID int64, m2 []byte) error {
+	r.srpMtx.Lock()
+	expected, ok := r.srpProofs[srpID]
+	delete(r.srpProofs, srpID)
+	r.srpMtx.Unlock()
+	if !ok {
+		return _errors.ErrUnknownSrpID
+	}
+	if subtle.ConstantTimeCompare(expected, m2) != 1 {
+		return _errors.ErrInvalidSrpM2
+	}
+	return nil
+}
+
+// NewSrpServer returns the server side of the password check of the algorithm, verifier is the one
+// which GenSrpHash returns. It is meant for the tests and the local server stubs.
+func NewSrpServer(algorithm int64, algorithmData []byte, verifier []byte) (*utils.SrpServer, error) {
+	algo, err := getPasswordAlgorithm(algorithm, algorithmData)
+	if err != nil {
+		return nil, err
+	}
+	p, g := algo.Group()
+	salt1, salt2 := algo.Salts()
+	return utils.NewSrpServer(p, g, salt1, salt2, verifier)
+}
+
+// srpGroup
+type srpGroup struct {
+	p, g         *big.Int
+	gen          int32
+	salt1, salt2 []byte
+}
+
+func newSrpGroup(p []byte, g int32, salt1, salt2 []byte) srpGroup {
+	return srpGroup{
+		p:     big.NewInt(0).SetBytes(p),
+		g:     big.NewInt(int64(g)),
+		gen:   g,
+		salt1: salt1,
+		salt2: salt2,
+	}
+}
+
+func (sg srpGroup) Group() (p, g *big.Int) {
+	return sg.p, sg.g
+}
+
+func (sg srpGroup) Salts() (salt1, salt2 []byte) {
+	return sg.salt1, sg.salt2
+}
+
+// passwordAlgorithmVer6A
+type passwordAlgorithmVer6A struct {
+	srpGroup
+}
+
+func newPasswordAlgorithmVer6A(algorithmData []byte) (PasswordAlgorithm, error) {
+	algo := &msg.PasswordAlgorithmVer6A{}
+	if err := algo.Unmarshal(algorithmData); err != nil {
+		return nil, err
+	}
+	return passwordAlgorithmVer6A{
+		srpGroup: newSrpGroup(algo.P, algo.G, algo.Salt1, algo.Salt2),
+	}, nil
+}
+
+func (pa passwordAlgorithmVer6A) X(password []byte) []byte {
+	return utils.PH2(password, pa.salt1, pa.salt2)
+}
+
+func (pa passwordAlgorithmVer6A) WithSalt1(salt1 []byte) PasswordAlgorithm {
+	pa.salt1 = salt1
+	return pa
+}
+
+func (pa passwordAlgorithmVer6A) Marshal() ([]byte, error) {
+	algo := &msg.PasswordAlgorithmVer6A{
+		Salt1: pa.salt1,
+		Salt2: pa.salt2,
+		G:     pa.gen,
+		P:     pa.p.Bytes(),
+	}
+	return algo.Marshal()
+}
+
+// passwordAlgorithmVer6AArgon2id
+type passwordAlgorithmVer6AArgon2id struct {
+	srpGroup
+	iterations  uint32
+	memory      uint32
+	parallelism uint8
+}
+
+func newPasswordAlgorithmVer6AArgon2id(algorithmData []byte) (PasswordAlgorithm, error) {
+	algo := &msg.PasswordAlgorithmVer6AArgon2Id{}
+	if err := algo.Unmarshal(algorithmData); err != nil {
+		return nil, err
+	}
+	// the parameters are bounded, otherwise the server could make the client run out of memory
+	if algo.Iterations == 0 || algo.Iterations > argon2MaxIterations ||
+		algo.Parallelism == 0 || algo.Parallelism > argon2MaxParallelism ||
+		algo.Memory < 8*algo.Parallelism || algo.Memory > argon2MaxMemory {
+		return nil, _errors.ErrInvalidPasswordAlgorithm
+	}
+	return passwordAlgorithmVer6AArgon2id{
+		srpGroup:    newSrpGroup(algo.P, algo.G, algo.Salt1, algo.Salt2),
+		iterations:  algo.Iterations,
+		memory:      algo.Memory,
+		parallelism: uint8(algo.Parallelism),
+	}, nil
+}
+
+func (pa passwordAlgorithmVer6AArgon2id) X(password []byte) []byte {
+	return utils.PH2Argon2id(password, pa.salt1, pa.salt2, pa.iterations, pa.memory, pa.parallelism)
+}
+
+func (pa passwordAlgorithmVer6AArgon2id) WithSalt1(salt1 []byte) PasswordAlgorithm {
+	pa.salt1 = salt1
+	return pa
+}
+
+func (pa passwordAlgorithmVer6AArgon2id) Marshal() ([]byte, error) {
+	algo := &msg.PasswordAlgorithmVer6AArgon2Id{
+		Salt1:       pa.salt1,
+		Salt2:       pa.salt2,
+		G:           pa.gen,
+		P:           pa.p.Bytes(),
+		Iterations:  pa.iterations,
+		Memory:      pa.memory,
+		Parallelism: uint32(pa.parallelism),
+	}
+	return algo.Marshal()
+}
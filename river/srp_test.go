@@ -0,0 +1,152 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/stub"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// srpKnownAnswer is a password check in the 2048 bits MODP group of RFC 3526 with the generator 2,
+// computed by a reference SRP-6a apart from this package. a and b are SHA-512 of the labels
+// "river/srp/a/0" || "river/srp/a/1" ..., B = k*v + g^b and the server secret S = (A * v^u)^b.
+var srpKnownAnswer = struct {
+	password, salt1, salt2, a, v, b, ga, m1, m2 string
+}{
+	password: "correct horse battery staple",
+	salt1:    "e3e80ac4cbb3210211ebc28c3c39fa8a9d77bc9b86b8b04e383fefbd3519cf8a",
+	salt2:    "202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f",
+	a:        "ce289c4d3e5fe58752b5ab9ddd9a8505fd31a10cb3c1f546fef3ecede1986c36ae2ce2913c659a31b7b718a87589f7db13be7a222d2ba3fe196221623be0064c209d0bcf6d224d9af911d4ead0ca79e4cbebb2a3ab6437837aa5229930cb2f197be97db2fedb638b2623bcf85cb94cc06ea1129984fb9e0f152d927eb0642f5197dde39db21ea0906dd8dc32e2a91669b11d30ede82853c8d0ce2aa559d0e9f436c928a6210846ff2a3fce86ca91a998cba66e31079303bcc2891a86b46b04924423c1d921a8300bd99bfb65c08e30571ff1a3d1bfbc28be6fef7331e55e7680d192850aafa79863899292e8adbeeb96ee5db1fe2ca455aad652e5c305bf5d73",
+	v:        "c81b8ee2acf80ab31ef87d8717b73c74a4f99310c822e72106a288fa342a03fb0eb42934f4fb3f56a6f6e6e5c9c15b926e209a3e2fa20e7635b7e58883fcbaa4ebc3515b51bb2b13b28787a1eafbcef9ef3189ddadc7cf4b88a343bc80f1355757578888d561c6eddb16d609eeba5021eae83bd4d828a8a1ffce71b7981634b44b202ece2eb48be6598ad345a07418b811dbcc1716c1f1717c32d549e5a2fc1ed3311e648f8dff37b43db34b4eee9894f45df65bfda10ed20e1fd2a32406c3dca06a734b04afc1a63b331f844bb6595b14bff52f1c17edac64879a26826fef0ca1a3b0781e79578b8a4611c1ec8915ff4eff1e4f5f2919800aeba4ba585dce82",
+	b:        "585fd9726dd1a297c5493197ae1abc1b5961e745a9b1a0276098b8e9cd963f9ee84ced1d9ba1e1187b17a02ad21100ee80de76cedd0ad44a79705cb6e1beaf261af554ba41efc9d4718291e8647cbd86140715f3522005fd8b81dcd1793e154024a61ee4f3b44159ee2370769540e5a9d2e7f2c4a35a0f7fffc191717925e7a8cad732c24e2c95bdd2aadaf33723687fb606f111d153541f5c0237a969e052ec05e116ce12ac2f2371666dcad7915cebbc26e103945c9a5e442bb5b29da35193b7ef8bbe6f33752d415da5ad631f2f3b639f7a67cb8505806962da34f56a128ac115f3cc24c97576241b99ee884af6d7341e95f233770643273660d2c112013c",
+	ga:       "1ae435cf2a2d91c7cad6b34f04de2160e01c09990961a5155b9d5e24bc2444d74788a3bad0a2e4df4ba8ed5fb4f1760ea10a534aa52fcac65a21f1b1b9863b812c66289b87629a3cd738ecf41d50ba2dd7ccbde7acc15d4941b2bbbcf174dca10aa4670bfa72739dec5f686c060d3b05bb30914a4a12283e17fc39eda034915da3d2236a673dda19060eccb2a6fe523f2b4c63bbe077469285eeddddf64c0cc5a8280f92cab5e0800b8e4a5a234469806a6761ef49ec0d4904d9a211c393a49e515ca540c35d4a6b0275638ae935e049fbf65a3e3aa7e172a841ffd14d540e8180c45b6fa7216adaab40b2cfef487afe49ee5b9a943984b37507cb31474712f7",
+	m1:       "870f7b8da3a61c9ec7890706a10c5250a0a5cc9b37bf27ddf65650fe79a7cfbc",
+	m2:       "ea49615222a96069191e6858f669a3a51f1c171c6ac9d37fd292524f59816fae",
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// srpAlgorithmData returns PasswordAlgorithmVer6A of the salts of the known answer
+func srpAlgorithmData(t *testing.T) []byte {
+	x := srpKnownAnswer
+	algorithmData, err := (&msg.PasswordAlgorithmVer6A{
+		Salt1: mustHex(t, x.salt1),
+		Salt2: mustHex(t, x.salt2),
+		G:     2,
+		P:     mustHex(t, stub.MODP2048),
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return algorithmData
+}
+
+// TestSrpKnownAnswer checks the verifier, and A, M1 and M2 of the pinned secret a against the reference
+func TestSrpKnownAnswer(t *testing.T) {
+	x := srpKnownAnswer
+	algorithmData := srpAlgorithmData(t)
+	r := NewRiver("srp")
+	verifier, err := r.GenSrpHash([]byte(x.password), msg.C_PasswordAlgorithmVer6A, algorithmData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(verifier, mustHex(t, x.v)) {
+		t.Fatalf("verifier %x, expected %s", verifier, x.v)
+	}
+
+	algo, err := getPasswordAlgorithm(msg.C_PasswordAlgorithmVer6A, algorithmData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ap := &msg.AccountPassword{
+		HasPassword:   true,
+		Algorithm:     msg.C_PasswordAlgorithmVer6A,
+		AlgorithmData: algorithmData,
+		SrpB:          mustHex(t, x.b),
+		SrpID:         77,
+	}
+	inputPassword, m2, err := genInputPassword(algo, ap, []byte(x.password), mustHex(t, x.a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inputPassword.SrpID != 77 || !bytes.Equal(inputPassword.A, mustHex(t, x.ga)) ||
+		!bytes.Equal(inputPassword.M1, mustHex(t, x.m1)) || !bytes.Equal(m2, mustHex(t, x.m2)) {
+		t.Fatalf("InputPassword %d A %x M1 %x M2 %x, expected the reference", inputPassword.SrpID,
+			inputPassword.A, inputPassword.M1, m2)
+	}
+
+	r.addSrpProof(77, m2)
+	bad := append([]byte(nil), m2...)
+	bad[0] ^= 1
+	if err = r.VerifySrpM2(77, bad); err != _errors.ErrInvalidSrpM2 {
+		t.Fatalf("a wrong M2 is verified by %v", err)
+	}
+	// each proof is verified once
+	if err = r.VerifySrpM2(77, m2); err != _errors.ErrUnknownSrpID {
+		t.Fatalf("M2 of a verified proof is checked by %v", err)
+	}
+	r.addSrpProof(77, m2)
+	if err = r.VerifySrpM2(77, m2); err != nil {
+		t.Fatalf("M2 of the reference is rejected: %v", err)
+	}
+}
+
+// TestSrpRandomSecret runs GenInputPassword against a server, the secret a must not be RandomData
+// of the server
+func TestSrpRandomSecret(t *testing.T) {
+	x := srpKnownAnswer
+	algorithmData := srpAlgorithmData(t)
+	r := NewRiver("srp")
+	verifier, err := r.GenSrpHash([]byte(x.password), msg.C_PasswordAlgorithmVer6A, algorithmData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := utils.NewSrpServer(big.NewInt(0).SetBytes(mustHex(t, stub.MODP2048)), big.NewInt(2),
+		mustHex(t, x.salt1), mustHex(t, x.salt2), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ap, err := (&msg.AccountPassword{
+		HasPassword:   true,
+		Algorithm:     msg.C_PasswordAlgorithmVer6A,
+		AlgorithmData: algorithmData,
+		SrpB:          server.B(),
+		RandomData:    mustHex(t, x.a),
+		SrpID:         78,
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := r.GenInputPassword([]byte(x.password), ap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputPassword := msg.InputPassword{}
+	if err = inputPassword.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(inputPassword.A, mustHex(t, x.ga)) {
+		t.Fatal("a is taken from RandomData")
+	}
+	m2, err := server.Verify(inputPassword.A, inputPassword.M1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.VerifySrpM2(78, m2); err != nil {
+		t.Fatalf("M2 of the server is rejected: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package river
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/stub"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/net/websocket"
+)
+
+// testStub
+// The stub server of the tests, its root key is set as the root key of the server keys
+type testStub struct {
+	*stub.Server
+	url string
+}
+
+func newTestStub(t testing.TB) *testStub {
+	return newTestStubWithPadding(t, river_conn.PaddingOAEPSHA256)
+}
+
+// newTestStubWithPadding returns the stub which RSA key is of the padding
+func newTestStubWithPadding(t testing.TB, padding string) *testStub {
+	s, err := stub.NewServerWithPadding(padding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	river_conn.SetRootPublicKey(s.RootPublicKey(), stub.Env)
+	return &testStub{Server: s, url: "ws" + strings.TrimPrefix(srv.URL, "http")}
+}
+
+// newRiver returns an account which has loaded the server keys of the stub and has no auth key
+func (s *testStub) newRiver(t testing.TB, handle string) *River {
+	r := NewRiver(handle)
+	if err := r.Load("{}", s.ServerKeys()); err != _errors.ErrNoAuthKey {
+		t.Fatalf("Load: %v", err)
+	}
+	return r
+}
+
+// testConn
+// A connection to the stub which waits for the reply of each request, the frames which are received
+// in between are kept in pushed. The requests are encoded for clusterID.
+type testConn struct {
+	r         *River
+	ws        *websocket.Conn
+	clusterID int32
+	pushed    []*msg.MessageEnvelope
+}
+
+func (s *testStub) dial(t testing.TB, r *River) *testConn {
+	ws, err := websocket.Dial(s.url, "", "http://127.0.0.1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	t.Cleanup(func() { _ = ws.Close() })
+	return &testConn{r: r, ws: ws}
+}
+
+// call sends the request and returns its reply
+func (c *testConn) call(constructor int64, body []byte) (*msg.MessageEnvelope, error) {
+	req := &msg.MessageEnvelope{
+		Constructor: constructor,
+		RequestID:   utils.RandomUint64(),
+		Message:     body,
+	}
+	frame, err := c.r.EncodeFor(c.clusterID, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(req.RequestID, frame)
+}
+
+// send sends the encoded frame of the request and returns its reply
+func (c *testConn) send(requestID uint64, frame []byte) (*msg.MessageEnvelope, error) {
+	if err := websocket.Message.Send(c.ws, frame); err != nil {
+		return nil, err
+	}
+	for {
+		env, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if env.RequestID == requestID {
+			return env, nil
+		}
+		c.pushed = append(c.pushed, env)
+	}
+}
+
+func (c *testConn) receive() (*msg.MessageEnvelope, error) {
+	var frame []byte
+	if err := websocket.Message.Receive(c.ws, &frame); err != nil {
+		return nil, err
+	}
+	return c.r.Decode(frame)
+}
+
+// expect sends the request and returns the message of its reply if it is of the constructor
+func (c *testConn) expect(constructor int64, body []byte, reply int64) ([]byte, error) {
+	res, err := c.call(constructor, body)
+	if err != nil {
+		return nil, err
+	}
+	if res.Constructor != reply {
+		return nil, fmt.Errorf("reply is %s, expected %s", msg.ConstructorName(res.Constructor), msg.ConstructorName(reply))
+	}
+	return res.Message, nil
+}
+
+// auth runs the handshake of id to the end, it could be called by any goroutine
+func (c *testConn) auth(id int64) error {
+	return c.handshake(id, c.r.AuthStep1(id, c.clusterID, func(int64) {}))
+}
+
+// authTemp runs the handshake of a temporary key to the end, the key is pending until it is bound
+func (c *testConn) authTemp(id int64) error {
+	return c.handshake(id, c.r.TempAuthStep1(id, 0, func(int64) {}))
+}
+
+func (c *testConn) handshake(id int64, step1 []byte) error {
+	progress := func(int64) {}
+	data, err := c.expect(msg.C_InitConnect, step1, msg.C_InitResponse)
+	if err != nil {
+		return err
+	}
+	req, err := c.r.AuthStep2(id, data, progress)
+	if err != nil {
+		return err
+	}
+	if data, err = c.expect(msg.C_InitCompleteAuth, req, msg.C_InitAuthCompleted); err != nil {
+		return err
+	}
+	_, err = c.r.AuthStep3(id, data, progress)
+	return err
+}
@@ -0,0 +1,207 @@
+package river
+
+import (
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTempKeyTTL is the lifetime of the temporary auth keys in seconds
+	DefaultTempKeyTTL int32 = 24 * 60 * 60
+	// tempKeyRotateRatio the temporary key gets rotated when this portion of its lifetime passed
+	tempKeyRotateRatio = 0.8
+)
+
+// tempKey
+// A short-lived auth key which is negotiated with the same handshake as the permanent key and
+// then is bound to it. Once bound, messages are encrypted with it, hence leaking the permanent
+// key does not expose the recorded traffic.
+type tempKey struct {
+	authID    int64
+	authKey   [256]byte
+	createdAt int64
+	expiresAt int64
+}
+
+// tempKeyRotation
+// The timer which asks for the next temporary key, closed is set when the account is removed
+type tempKeyRotation struct {
+	mtx    sync.Mutex
+	timer  *time.Timer
+	closed bool
+}
+
+// tempKeys
+// pending is negotiated but not bound yet, prev is kept to decode the in-flight responses after rotation
+type tempKeys struct {
+	pending *tempKey
+	active  *tempKey
+	prev    *tempKey
+}
+
+func (tks *tempKeys) setPending(authID int64, authKey [256]byte, createdAt, expiresAt int64) {
+	tks.pending = &tempKey{
+		authID:    authID,
+		authKey:   authKey,
+		createdAt: createdAt,
+		expiresAt: expiresAt,
+	}
+}
+
+// TempAuthStep1 starts the handshake of a temporary auth key with the default cluster which expires in ttl seconds
+func (r *River) TempAuthStep1(id int64, ttl int32, cb Callback) []byte {
+	if ttl <= 0 {
+		ttl = DefaultTempKeyTTL
+	}
+	return r.authStep1(id, river_conn.DefaultClusterID, ttl, cb)
+}
+
+// BindTempKey returns the encoded AuthBindTempKey request which binds the pending temporary key to
+// the permanent key. The inner message is encrypted with the permanent key and the request itself is
+// encrypted with the temporary key, so the server could verify both keys belong to the same client.
+func (r *River) BindTempKey(requestID uint64) (bytes []byte, err error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	tk := r.tempKeys.pending
+	if tk == nil {
+		return nil, _errors.ErrNoTempKey
+	}
+	if r.authID == 0 {
+		return nil, _errors.ErrNoAuthKey
+	}
+
+	nonce := utils.RandomUint64()
+	inner := msg.AuthBindTempKeyInner{
+		Nonce:         nonce,
+		TempAuthID:    tk.authID,
+		PermAuthID:    r.authID,
+		TempSessionID: r.sessionID,
+		ExpiresAt:     tk.expiresAt,
+	}
+	innerEnvelope := &msg.MessageEnvelope{
+		Constructor: msg.C_AuthBindTempKeyInner,
+		RequestID:   requestID,
+	}
+	innerEnvelope.Message, err = inner.Marshal()
+	if err != nil {
+		return
+	}
+
+	req := msg.AuthBindTempKey{
+		PermAuthID: r.authID,
+		Nonce:      nonce,
+		ExpiresAt:  tk.expiresAt,
+	}
+	// the inner message is sealed rather than encoded, it is sent inside the request and is no frame
+	sealed, err := r.seal(r.authID, r.authKey, innerEnvelope)
+	if err != nil {
+		return
+	}
+	req.EncryptedMessage, err = sealed.Marshal()
+	if err != nil {
+		return
+	}
+
+	envelope := &msg.MessageEnvelope{
+		Constructor: msg.C_AuthBindTempKey,
+		RequestID:   requestID,
+	}
+	envelope.Message, err = req.Marshal()
+	if err != nil {
+		return
+	}
+
+	return r.encode(tk.authID, tk.authKey[:], envelope)
+}
+
+// TempKeyBound must be called when the server accepted the AuthBindTempKey request. From now on
+// the messages of the default cluster are encrypted with the temporary key.
+func (r *River) TempKeyBound() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.tempKeys.pending == nil {
+		return _errors.ErrNoTempKey
+	}
+	r.tempKeys.prev = r.tempKeys.active
+	r.tempKeys.active = r.tempKeys.pending
+	r.tempKeys.pending = nil
+	return nil
+}
+
+// TempKeyRotateIn returns the duration after which a new temporary key must be negotiated
+func (r *River) TempKeyRotateIn() time.Duration {
+	r.mtx.RLock()
+	tk := r.tempKeys.active
+	r.mtx.RUnlock()
+	if tk == nil {
+		return 0
+	}
+	rotateAt := tk.createdAt + int64(float64(tk.expiresAt-tk.createdAt)*tempKeyRotateRatio)
+	d := rotateAt - r.ConnInfo.Now()
+	if d < 0 {
+		return 0
+	}
+	return time.Duration(d) * time.Second
+}
+
+// ScheduleTempKeyRotation calls rotate when the bound temporary key must be rotated, it replaces the
+// previous schedule. Nothing is scheduled once the account is closed.
+func (r *River) ScheduleTempKeyRotation(rotate func()) {
+	d := r.TempKeyRotateIn()
+	r.rotation.mtx.Lock()
+	defer r.rotation.mtx.Unlock()
+	if r.rotation.timer != nil {
+		r.rotation.timer.Stop()
+	}
+	if r.rotation.closed {
+		return
+	}
+	r.rotation.timer = time.AfterFunc(d, rotate)
+}
+
+// stopTempKeyRotation stops the scheduled rotation for good
+func (r *River) stopTempKeyRotation() {
+	r.rotation.mtx.Lock()
+	defer r.rotation.mtx.Unlock()
+	r.rotation.closed = true
+	if r.rotation.timer != nil {
+		r.rotation.timer.Stop()
+		r.rotation.timer = nil
+	}
+}
+
+// DropTempKeys forgets all the temporary keys, messages will be encrypted with the permanent key. It is
+// the explicit way back to the permanent key once the bound key is expired and could not be rotated.
+func (r *River) DropTempKeys() {
+	r.mtx.Lock()
+	r.tempKeys = tempKeys{}
+	r.mtx.Unlock()
+}
+
+// activeTempKey returns the bound temporary key, or ErrTempKeyExpired if it is expired, so the messages
+// never fall back to the permanent key silently. r.mtx must be held.
+func (r *River) activeTempKey() (*tempKey, error) {
+	tk := r.tempKeys.active
+	if tk == nil {
+		return nil, nil
+	}
+	if tk.expiresAt <= r.ConnInfo.Now() {
+		return nil, _errors.ErrTempKeyExpired
+	}
+	return tk, nil
+}
+
+// tempKeyByAuthID returns the temporary key of the auth id if it is not expired yet, r.mtx must be held
+func (r *River) tempKeyByAuthID(authID int64) []byte {
+	now := r.ConnInfo.Now()
+	for _, tk := range []*tempKey{r.tempKeys.active, r.tempKeys.pending, r.tempKeys.prev} {
+		if tk != nil && tk.authID == authID && tk.expiresAt > now {
+			return tk.authKey[:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package river
+
+import (
+	"bytes"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+)
+
+// TestExpiredTempKeys checks that the frames of the expired temporary keys are not decrypted
+func TestExpiredTempKeys(t *testing.T) {
+	conn, err := river_conn.NewRiverConnection("tempkey", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := int64(1600000000)
+	conn.SetLocalClock(func() int64 { return now * 1000 })
+	r := NewRiver("tempkey")
+	r.ConnInfo = conn
+	r.tempKeys.pending = &tempKey{authID: 1, authKey: [256]byte{1}, createdAt: now - 100, expiresAt: now + 100}
+	r.tempKeys.active = &tempKey{authID: 2, authKey: [256]byte{2}, createdAt: now - 100, expiresAt: now + 100}
+	r.tempKeys.prev = &tempKey{authID: 3, authKey: [256]byte{3}, createdAt: now - 200, expiresAt: now + 1}
+
+	for authID := int64(1); authID <= 3; authID++ {
+		key, err := r.keyByAuthID(authID)
+		if err != nil {
+			t.Fatalf("the key of %d is not looked up: %v", authID, err)
+		}
+		if !bytes.Equal(key[:1], []byte{byte(authID)}) {
+			t.Fatalf("the key of %d starts with %v", authID, key[:1])
+		}
+	}
+	// the previous key expires first, then the active and the pending ones
+	for _, tt := range []struct {
+		now     int64
+		expired []int64
+		valid   []int64
+	}{
+		{now + 1, []int64{3}, []int64{1, 2}},
+		{now + 100, []int64{1, 2, 3}, nil},
+	} {
+		now = tt.now
+		for _, authID := range tt.expired {
+			if _, err := r.keyByAuthID(authID); err != _errors.ErrNoAuthKey {
+				t.Errorf("at %d the expired key of %d is looked up by %v", now, authID, err)
+			}
+		}
+		for _, authID := range tt.valid {
+			if _, err := r.keyByAuthID(authID); err != nil {
+				t.Errorf("at %d the key of %d is not looked up: %v", now, authID, err)
+			}
+		}
+	}
+	if _, err = r.activeTempKey(); err != _errors.ErrTempKeyExpired {
+		t.Fatalf("the expired active key is looked up by %v", err)
+	}
+}
+
+// TestEncodeExpiredTempKey checks the frames are not encrypted by the permanent key once the bound
+// temporary key is expired, until the temporary keys are dropped
+func TestEncodeExpiredTempKey(t *testing.T) {
+	conn, err := river_conn.NewRiverConnection("tempkey", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := int64(1600000000)
+	conn.SetLocalClock(func() int64 { return now * 1000 })
+	r := NewRiver("tempkey")
+	r.ConnInfo = conn
+	r.authID, r.authKey = 1, make([]byte, 256)
+	r.tempKeys.active = &tempKey{authID: 2, authKey: [256]byte{2}, createdAt: now - 100, expiresAt: now + 100}
+
+	encodedBy := func() (int64, error) {
+		frame, err := r.Encode(&msg.MessageEnvelope{Constructor: msg.C_Error})
+		if err != nil {
+			return 0, err
+		}
+		m := msg.ProtoMessage{}
+		err = m.Unmarshal(frame)
+		return m.AuthID, err
+	}
+	if authID, err := encodedBy(); err != nil || authID != 2 {
+		t.Fatalf("encoded by %d %v, expected the temporary key", authID, err)
+	}
+	now += 100
+	if authID, err := encodedBy(); err != _errors.ErrTempKeyExpired {
+		t.Fatalf("encoded by %d %v after the temporary key expired", authID, err)
+	}
+	// the handshake is not encrypted, it is encoded to negotiate the next key
+	if _, err = r.Encode(&msg.MessageEnvelope{Constructor: msg.C_InitConnect}); err != nil {
+		t.Fatal(err)
+	}
+	r.DropTempKeys()
+	if authID, err := encodedBy(); err != nil || authID != 1 {
+		t.Fatalf("encoded by %d %v, expected the permanent key once the temporary keys are dropped", authID, err)
+	}
+}
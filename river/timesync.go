@@ -0,0 +1,99 @@
+package river
+
+import (
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"sync"
+	"sync/atomic"
+)
+
+// timeRequestTTL is the time in milliseconds after which an unanswered SystemGetServerTime is forgotten
+const timeRequestTTL = 60 * 1000
+
+// timeRequests
+// Local time in milliseconds of the SystemGetServerTime requests in flight by their request ids
+type timeRequests struct {
+	mtx    sync.Mutex
+	sentAt map[uint64]int64
+}
+
+func (tr *timeRequests) add(requestID uint64, now int64) {
+	tr.mtx.Lock()
+	defer tr.mtx.Unlock()
+	if tr.sentAt == nil {
+		tr.sentAt = make(map[uint64]int64)
+	}
+	for id, t := range tr.sentAt {
+		if now-t > timeRequestTTL {
+			delete(tr.sentAt, id)
+		}
+	}
+	tr.sentAt[requestID] = now
+}
+
+func (tr *timeRequests) remove(requestID uint64) (sentAt int64, ok bool) {
+	tr.mtx.Lock()
+	defer tr.mtx.Unlock()
+	sentAt, ok = tr.sentAt[requestID]
+	delete(tr.sentAt, requestID)
+	return
+}
+
+// onServerTime adds the time sample of the SystemServerTime reply
+func (r *River) onServerTime(env *msg.MessageEnvelope) {
+	receivedAt := r.localTime()
+	sentAt, ok := r.timeRequests.remove(env.RequestID)
+	if !ok {
+		return
+	}
+	x := msg.SystemServerTime{}
+	if err := x.Unmarshal(env.Message); err != nil {
+		return
+	}
+	r.addTimeSample(x.Timestamp, sentAt, receivedAt)
+}
+
+// addTimeSample adds the time sample to the connection info, which is saved if DiffTime changed enough
+func (r *River) addTimeSample(serverTime, sentAt, receivedAt int64) {
+	if r.ConnInfo.AddTimeSample(serverTime, sentAt, receivedAt) {
+		r.saveConnInfo()
+	}
+}
+
+// SetServerTime sets the server time in seconds which is measured by the app
+func (r *River) SetServerTime(timestamp int64) {
+	if r.ConnInfo.SetServerTime(timestamp) {
+		r.saveConnInfo()
+	}
+}
+
+// saveConnInfo saves the connection info of an authorized account, r.mtx keeps the keys from being
+// changed by a handshake while they are encoded
+func (r *River) saveConnInfo() {
+	r.mtx.Lock()
+	if r.ConnInfo.AuthID != 0 {
+		r.ConnInfo.Save()
+	}
+	r.mtx.Unlock()
+}
+
+// localTime returns the local time in milliseconds by the clock of the connection info if it is loaded
+func (r *River) localTime() int64 {
+	if r.ConnInfo == nil {
+		return river_conn.LocalTime()
+	}
+	return r.ConnInfo.LocalTime()
+}
+
+// checkClockSkew flags a time sync if the server time of the message is too far from our estimation
+func (r *River) checkClockSkew(messageID uint64) {
+	if r.ConnInfo.CheckClockSkew(int64(messageID >> 32)) {
+		atomic.StoreInt32(&r.timeSyncNeeded, 1)
+	}
+}
+
+// NeedsTimeSync returns true once after Decode found the clock is skewed, then SystemGetServerTime
+// must be sent to sync the time again
+func (r *River) NeedsTimeSync() bool {
+	return atomic.CompareAndSwapInt32(&r.timeSyncNeeded, 1, 0)
+}
@@ -0,0 +1,208 @@
+package river
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+)
+
+// savedDiffTimes captures the connection info which is saved and returns DiffTime of each save
+func savedDiffTimes(t *testing.T, storageKey string) func() []int64 {
+	var (
+		mtx   sync.Mutex
+		saved []int64
+	)
+	river_conn.SetStorage(func(data, key string) {
+		if key != storageKey {
+			return
+		}
+		v := river_conn.RiverConnectionV2{}
+		if err := v.UnmarshalJSON([]byte(data)); err != nil {
+			t.Errorf("the saved connection info %s: %v", data, err)
+		}
+		mtx.Lock()
+		saved = append(saved, v.DiffTime)
+		mtx.Unlock()
+	})
+	t.Cleanup(func() { river_conn.SetStorage(func(data, key string) {}) })
+	return func() []int64 {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]int64(nil), saved...)
+	}
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestTimeSampleSave checks the offset which the samples are estimated to is saved once it moved enough,
+// then runs the saves while the cluster keys are changed as by AuthStep3, go test -race reports the
+// connection info which is saved without r.mtx
+func TestTimeSampleSave(t *testing.T) {
+	conn, err := river_conn.NewRiverConnection("timesync", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := int64(1000000)
+	conn.SetLocalClock(func() int64 { return atomic.LoadInt64(&now) })
+	conn.SetClusterKey(river_conn.DefaultClusterID, 1, [256]byte{1})
+	r := NewRiver("timesync")
+	r.ConnInfo = conn
+	saved := savedDiffTimes(t, conn.StorageKey())
+
+	// the offset of a sample is the server time in the middle of its second minus the middle of the round trip
+	offset := int64(2000*1000 + 500 - 999100)
+	tests := []struct {
+		name                           string
+		serverTime, sentAt, receivedAt int64
+		saved                          []int64
+	}{
+		{"first", 2000, 999000, 999200, []int64{offset}},
+		{"same offset", 2001, 1000000, 1000200, []int64{offset}},
+		// the median of the faster half drops the slow sample
+		{"slow outlier", 9000, 990000, 1000000, []int64{offset}},
+		// the faster half is the fast sample and one of the first ones, the upper median is taken
+		{"fast", 3000, 1000000, 1000000, []int64{offset, 3000*1000 + 500 - 1000000}},
+		{"fast again", 3000, 1000000, 1000000, []int64{offset, 3000*1000 + 500 - 1000000}},
+		{"invalid", 0, 1000000, 1000000, []int64{offset, 3000*1000 + 500 - 1000000}},
+	}
+	for _, tt := range tests {
+		r.addTimeSample(tt.serverTime, tt.sentAt, tt.receivedAt)
+		if s := saved(); !equalInt64s(s, tt.saved) {
+			t.Fatalf("%s: saved %v, expected %v", tt.name, s, tt.saved)
+		}
+	}
+	if d := atomic.LoadInt64(&conn.DiffTime); d != 3000*1000+500-1000000 {
+		t.Fatalf("DiffTime %d", d)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := int64(0); i < 100; i++ {
+			atomic.AddInt64(&now, 1000)
+			ms := r.localTime()
+			r.addTimeSample(ms/1000+(i%2)*100, ms, ms)
+			r.SetServerTime(ms/1000 + (i%2)*100)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := int32(0); i < 100; i++ {
+			r.mtx.Lock()
+			r.ConnInfo.SetClusterKey(2+i%4, int64(i), [256]byte{byte(i)})
+			r.mtx.Unlock()
+		}
+	}()
+	wg.Wait()
+	if len(saved()) <= len(tests[len(tests)-1].saved) {
+		t.Fatal("the samples which moved DiffTime are not saved")
+	}
+}
+
+// skewedFrame returns a frame encrypted by the auth key which the server stamped at serverTime seconds
+func skewedFrame(t *testing.T, authID int64, authKey []byte, serverTime int64, env *msg.MessageEnvelope) []byte {
+	plain, err := (&msg.ProtoEncryptedPayload{
+		ServerSalt: 1,
+		SessionID:  2,
+		MessageID:  uint64(serverTime)<<32 | 1,
+		Envelope:   env,
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := utils.Encrypt(authKey, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := (&msg.ProtoMessage{
+		AuthID:     authID,
+		MessageKey: utils.GenerateMessageKey(authKey, plain),
+		Payload:    encrypted,
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+// TestNeedsTimeSyncOnDecode decodes the messages which the server stamped away from our clock, a single
+// sync is asked for each resync interval, and the reply of SystemGetServerTime syncs the clock
+func TestNeedsTimeSyncOnDecode(t *testing.T) {
+	conn, err := river_conn.NewRiverConnection("timesync", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := int64(1700000000 * 1000)
+	conn.SetLocalClock(func() int64 { return now })
+	authKey := make([]byte, 256)
+	authKey[0] = 1
+	r := NewRiver("timesync")
+	r.ConnInfo = conn
+	r.authID, r.authKey = 1, authKey
+	update := &msg.MessageEnvelope{Constructor: msg.C_UpdateContainer}
+
+	tests := []struct {
+		name       string
+		advance    int64
+		serverTime int64
+		sync       bool
+	}{
+		{"in time", 0, 1700000000, false},
+		{"ahead", 0, 1700000000 + 3600, true},
+		{"ahead in the interval", 1000, 1700000000 + 3600, false},
+		{"behind after the interval", 60 * 1000, 1700000000 - 3600, true},
+	}
+	for _, tt := range tests {
+		now += tt.advance
+		if _, err = r.Decode(skewedFrame(t, 1, authKey, tt.serverTime, update)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if sync := r.NeedsTimeSync(); sync != tt.sync {
+			t.Fatalf("%s: NeedsTimeSync %v", tt.name, sync)
+		}
+		// a sync is asked for once
+		if r.NeedsTimeSync() {
+			t.Fatalf("%s: NeedsTimeSync is true twice", tt.name)
+		}
+	}
+
+	// the server runs an hour ahead, its reply to SystemGetServerTime syncs the clock once the interval passed
+	now += 60 * 1000
+	req := &msg.MessageEnvelope{Constructor: msg.C_SystemGetServerTime, RequestID: 9}
+	if _, err = r.Encode(req); err != nil {
+		t.Fatal(err)
+	}
+	serverNow := now/1000 + 3600
+	data, _ := (&msg.SystemServerTime{Timestamp: serverNow}).Marshal()
+	reply := &msg.MessageEnvelope{Constructor: msg.C_SystemServerTime, RequestID: 9, Message: data}
+	if _, err = r.Decode(skewedFrame(t, 1, authKey, serverNow, reply)); err != nil {
+		t.Fatal(err)
+	}
+	if r.NeedsTimeSync() {
+		t.Fatal("the reply of the sync asks for another sync")
+	}
+	if d := atomic.LoadInt64(&conn.DiffTime); d != 3600*1000+500 {
+		t.Fatalf("DiffTime %d after the sync", d)
+	}
+	if _, err = r.Decode(skewedFrame(t, 1, authKey, serverNow, update)); err != nil {
+		t.Fatal(err)
+	}
+	if r.NeedsTimeSync() {
+		t.Fatal("a message in time asks for a sync after the clock is synced")
+	}
+}
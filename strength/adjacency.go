@@ -0,0 +1,102 @@
+package strength
+
+import (
+	"strings"
+)
+
+// Keyboard layouts of the spatial matching
+const (
+	graphQwerty = "qwerty"
+	graphKeypad = "keypad"
+)
+
+// The keys are written with their shifted characters, the rows of qwerty are slanted
+const (
+	qwertyLayout = "" +
+		"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+\n" +
+		"    qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|\n" +
+		"     aA sS dD fF gG hH jJ kK lL ;: '\"\n" +
+		"      zZ xX cC vV bB nN mM ,< .> /?"
+	keypadLayout = "" +
+		"  / * -\n" +
+		"7 8 9 +\n" +
+		"4 5 6\n" +
+		"1 2 3\n" +
+		"  0 ."
+)
+
+var (
+	// adjacencyGraphs maps each character to its neighbor keys, the neighbors are listed by their
+	// direction and an empty string means there is no key in that direction
+	adjacencyGraphs = map[string]map[rune][]string{
+		graphQwerty: buildAdjacencyGraph(qwertyLayout, true),
+		graphKeypad: buildAdjacencyGraph(keypadLayout, false),
+	}
+	keyboardAverageDegree = averageDegree(adjacencyGraphs[graphQwerty])
+	keypadAverageDegree   = averageDegree(adjacencyGraphs[graphKeypad])
+)
+
+type keyPosition struct {
+	x, y int
+}
+
+func buildAdjacencyGraph(layout string, slanted bool) map[rune][]string {
+	positions := make(map[keyPosition]string)
+	tokenSize := 0
+	for y, line := range strings.Split(layout, "\n") {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		for x := 0; x < len(line); {
+			if line[x] == ' ' {
+				x++
+				continue
+			}
+			end := strings.IndexByte(line[x:], ' ')
+			if end < 0 {
+				end = len(line) - x
+			}
+			token := line[x : x+end]
+			tokenSize = len(token)
+			positions[keyPosition{x: (x - slant) / (tokenSize + 1), y: y}] = token
+			x += end
+		}
+	}
+
+	graph := make(map[rune][]string)
+	for pos, token := range positions {
+		var neighbors []keyPosition
+		if slanted {
+			neighbors = []keyPosition{
+				{pos.x - 1, pos.y}, {pos.x, pos.y - 1}, {pos.x + 1, pos.y - 1},
+				{pos.x + 1, pos.y}, {pos.x, pos.y + 1}, {pos.x - 1, pos.y + 1},
+			}
+		} else {
+			neighbors = []keyPosition{
+				{pos.x - 1, pos.y}, {pos.x - 1, pos.y - 1}, {pos.x, pos.y - 1}, {pos.x + 1, pos.y - 1},
+				{pos.x + 1, pos.y}, {pos.x + 1, pos.y + 1}, {pos.x, pos.y + 1}, {pos.x - 1, pos.y + 1},
+			}
+		}
+		adjacents := make([]string, len(neighbors))
+		for i, n := range neighbors {
+			adjacents[i] = positions[n]
+		}
+		for _, r := range token {
+			graph[r] = adjacents
+		}
+	}
+	return graph
+}
+
+func averageDegree(graph map[rune][]string) float64 {
+	total := 0
+	for _, adjacents := range graph {
+		for _, a := range adjacents {
+			if a != "" {
+				total++
+			}
+		}
+	}
+	return float64(total) / float64(len(graph))
+}
@@ -0,0 +1,73 @@
+package strength
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// easyjsonSample
+// A value which JSON is kept in testdata/easyjson as easyjson wrote it, decoded is a pointer to the zero
+// value of its type
+type easyjsonSample struct {
+	name    string
+	value   interface{ MarshalJSON() ([]byte, error) }
+	decoded interface{ UnmarshalJSON([]byte) error }
+	encodes bool
+}
+
+// easyjsonText has the characters which are escaped
+const easyjsonText = "quote \" backslash \\ <tag> & amp \u2028 \u2029 \x01\t\n\r سلام 😀 /"
+
+func easyjsonSamples() []easyjsonSample {
+	return []easyjsonSample{
+		{"Result", Result{
+			Score:        4,
+			Guesses:      1e20,
+			GuessesLog10: 20.000000000000004,
+			CrackTimes: CrackTimes{
+				OnlineThrottling:   math.MaxFloat64,
+				OnlineNoThrottling: 1.5e-7,
+				OfflineSlowHashing: 123456789.125,
+				OfflineFastHashing: 0.1,
+				Display:            "centuries",
+			},
+			Warning:     easyjsonText,
+			Suggestions: []string{"Add another word or two", ""},
+			Patterns:    []string{},
+		}, &Result{}, true},
+		{"ResultEmpty", Result{}, &Result{}, true},
+		{"ResultSmall", Result{Guesses: 5e-324, GuessesLog10: -1, CrackTimes: CrackTimes{OnlineThrottling: 1e21}},
+			&Result{}, true},
+	}
+}
+
+// TestEasyjsonEquivalence checks jsonx writes the JSON which easyjson wrote for the same values, and
+// decodes it back to them
+func TestEasyjsonEquivalence(t *testing.T) {
+	for _, s := range easyjsonSamples() {
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", "easyjson", s.name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.encodes {
+			data, err := s.value.MarshalJSON()
+			if err != nil {
+				t.Fatalf("%s: %v", s.name, err)
+			}
+			if !bytes.Equal(data, expected) {
+				t.Errorf("%s is written as\n%s\neasyjson wrote\n%s", s.name, data, expected)
+			}
+		}
+		if err = s.decoded.UnmarshalJSON(expected); err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		if decoded := reflect.ValueOf(s.decoded).Elem().Interface(); !reflect.DeepEqual(decoded, s.value) {
+			t.Errorf("%s is decoded as\n%#v\nexpected\n%#v", s.name, decoded, s.value)
+		}
+	}
+}
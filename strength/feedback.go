@@ -0,0 +1,110 @@
+package strength
+
+import (
+	"unicode"
+)
+
+// Suggestions of the feedback
+const (
+	suggestionDefault1     = "Use a few words, avoid common phrases"
+	suggestionDefault2     = "No need for symbols, digits, or uppercase letters"
+	suggestionAddWord      = "Add another word or two. Uncommon words are better."
+	suggestionSpatial      = "Use a longer keyboard pattern with more turns"
+	suggestionRepeat       = "Avoid repeated words and characters"
+	suggestionSequence     = "Avoid sequences"
+	suggestionRecentYears  = "Avoid recent years"
+	suggestionDates        = "Avoid dates and years that are associated with you"
+	suggestionCapitalize   = "Capitalization doesn't help very much"
+	suggestionAllUppercase = "All-uppercase is almost as easy to guess as all-lowercase"
+	suggestionReversed     = "Reversed words aren't much harder to guess"
+	suggestionL33t         = "Predictable substitutions like '@' instead of 'a' don't help very much"
+)
+
+// feedback returns a warning and the suggestions about the weakest part of the password, nothing is
+// returned for the safe passwords
+func feedback(score int, sequence []*match) (warning string, suggestions []string) {
+	if len(sequence) == 0 {
+		return "", []string{suggestionDefault1, suggestionDefault2}
+	}
+	if score > ScoreSomewhatGuessable {
+		return "", nil
+	}
+
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if len(m.token) > len(longest.token) {
+			longest = m
+		}
+	}
+	warning, suggestions = matchFeedback(longest, len(sequence) == 1)
+	return warning, append([]string{suggestionAddWord}, suggestions...)
+}
+
+func matchFeedback(m *match, soleMatch bool) (warning string, suggestions []string) {
+	switch m.pattern {
+	case patternDictionary:
+		return dictionaryFeedback(m, soleMatch)
+	case patternSpatial:
+		if m.turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		} else {
+			warning = "Short keyboard patterns are easy to guess"
+		}
+		return warning, []string{suggestionSpatial}
+	case patternRepeat:
+		if len(m.baseToken) == 1 {
+			warning = "Repeats like \"aaa\" are easy to guess"
+		} else {
+			warning = "Repeats like \"abcabcabc\" are only slightly harder to guess than \"abc\""
+		}
+		return warning, []string{suggestionRepeat}
+	case patternSequence:
+		return "Sequences like abc or 6543 are easy to guess", []string{suggestionSequence}
+	case patternRegex:
+		return "Recent years are easy to guess", []string{suggestionRecentYears, suggestionDates}
+	case patternDate:
+		return "Dates are often easy to guess", []string{suggestionDates}
+	}
+	return "", nil
+}
+
+func dictionaryFeedback(m *match, soleMatch bool) (warning string, suggestions []string) {
+	switch m.dictionaryName {
+	case dictPasswords:
+		switch {
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 10:
+			warning = "This is a top-10 common password"
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 100:
+			warning = "This is a top-100 common password"
+		case soleMatch && !m.l33t && !m.reversed:
+			warning = "This is a very common password"
+		case m.guesses <= 1e4:
+			warning = "This is similar to a commonly used password"
+		}
+	case dictEnglish:
+		if soleMatch {
+			warning = "A word by itself is easy to guess"
+		}
+	case dictNames:
+		if soleMatch {
+			warning = "Names and surnames by themselves are easy to guess"
+		} else {
+			warning = "Common names and surnames are easy to guess"
+		}
+	case dictUserInputs:
+		warning = "Your name and phone number are easy to guess"
+	}
+
+	if unicode.IsUpper(m.token[0]) && !isAll(string(m.token[1:]), unicode.IsUpper) {
+		suggestions = append(suggestions, suggestionCapitalize)
+	} else if isAll(string(m.token), unicode.IsUpper) && len(m.token) > 1 {
+		suggestions = append(suggestions, suggestionAllUppercase)
+	}
+	if m.reversed && len(m.token) >= 4 {
+		suggestions = append(suggestions, suggestionReversed)
+	}
+	if m.l33t {
+		suggestions = append(suggestions, suggestionL33t)
+	}
+	return warning, suggestions
+}
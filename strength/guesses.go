@@ -0,0 +1,197 @@
+package strength
+
+import (
+	"math"
+	"time"
+	"unicode"
+)
+
+const (
+	bruteforceCardinality     = 10
+	minSubmatchGuessesSingle  = 10
+	minSubmatchGuessesMulti   = 50
+	minYearSpace              = 20
+	dateSeparatorMultiplier   = 4
+	keyboardStartingPositions = 94
+)
+
+// referenceYear is the year which the recent years and dates are guessed around
+var referenceYear = time.Now().Year()
+
+// estimateGuesses returns the number of guesses of the match, the submatches of the password get at least
+// a few guesses, otherwise a sequence of them would be underestimated
+func estimateGuesses(m *match, password []rune) float64 {
+	if m.guesses != 0 {
+		return m.guesses
+	}
+	minGuesses := 1.0
+	if len(m.token) < len(password) {
+		if len(m.token) == 1 {
+			minGuesses = minSubmatchGuessesSingle
+		} else {
+			minGuesses = minSubmatchGuessesMulti
+		}
+	}
+
+	var guesses float64
+	switch m.pattern {
+	case patternBruteforce:
+		guesses = bruteforceGuesses(m)
+	case patternDictionary:
+		guesses = dictionaryGuesses(m)
+	case patternSpatial:
+		guesses = spatialGuesses(m)
+	case patternRepeat:
+		guesses = m.baseGuesses * float64(m.repeatCount)
+	case patternSequence:
+		guesses = sequenceGuesses(m)
+	case patternRegex:
+		guesses = math.Max(float64(absInt(atoi(string(m.token))-referenceYear)), minYearSpace)
+	case patternDate:
+		guesses = math.Max(float64(absInt(m.year-referenceYear)), minYearSpace) * 365
+		if m.separator != "" {
+			guesses *= dateSeparatorMultiplier
+		}
+	}
+	m.guesses = math.Max(guesses, minGuesses)
+	return m.guesses
+}
+
+func bruteforceGuesses(m *match) float64 {
+	guesses := math.Pow(bruteforceCardinality, float64(len(m.token)))
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+	// the bruteforce matches must not beat the other matches of the same length
+	minGuesses := float64(minSubmatchGuessesMulti + 1)
+	if len(m.token) == 1 {
+		minGuesses = minSubmatchGuessesSingle + 1
+	}
+	return math.Max(guesses, minGuesses)
+}
+
+func dictionaryGuesses(m *match) float64 {
+	guesses := float64(m.rank) * uppercaseVariations(m.token) * l33tVariations(m)
+	if m.reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+// uppercaseVariations the common capitalizations are the first, the last or all of the letters
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	n := len(token)
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[n-1]))) {
+		return 2
+	}
+	variations := 0.0
+	for i := 1; i <= upper && i <= lower; i++ {
+		variations += nCk(upper+lower, i)
+	}
+	return variations
+}
+
+func l33tVariations(m *match) float64 {
+	if !m.l33t {
+		return 1
+	}
+	variations := 1.0
+	for subbed, unsubbed := range m.sub {
+		s, u := 0, 0
+		for _, r := range m.token {
+			switch unicode.ToLower(r) {
+			case subbed:
+				s++
+			case unsubbed:
+				u++
+			}
+		}
+		if s == 0 || u == 0 {
+			// all substituted or all not, the attacker tries both
+			variations *= 2
+			continue
+		}
+		possibilities := 0.0
+		for i := 1; i <= s && i <= u; i++ {
+			possibilities += nCk(s+u, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func spatialGuesses(m *match) float64 {
+	s := float64(keyboardStartingPositions)
+	d := keyboardAverageDegree
+	if m.graph == graphKeypad {
+		s = float64(len(adjacencyGraphs[graphKeypad]))
+		d = keypadAverageDegree
+	}
+	guesses := 0.0
+	l := len(m.token)
+	for i := 2; i <= l; i++ {
+		for j := 1; j <= m.turns && j <= i-1; j++ {
+			guesses += nCk(i-1, j-1) * s * math.Pow(d, float64(j))
+		}
+	}
+	if m.shiftedCount > 0 {
+		shifted := m.shiftedCount
+		unshifted := l - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for i := 1; i <= shifted && i <= unshifted; i++ {
+				variations += nCk(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+func sequenceGuesses(m *match) float64 {
+	var base float64
+	switch m.token[0] {
+	case 'a', 'A', 'z', 'Z', '0', '1', '9':
+		// the obvious starts
+		base = 4
+	default:
+		if unicode.IsDigit(m.token[0]) {
+			base = 10
+		} else {
+			base = 26
+		}
+	}
+	if !m.ascending {
+		base *= 2
+	}
+	return base * float64(len(m.token))
+}
+
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	r := 1.0
+	for d := 1; d <= k; d++ {
+		r *= float64(n)
+		r /= float64(d)
+		n--
+	}
+	return r
+}
@@ -0,0 +1,662 @@
+package strength
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Patterns of the matches
+const (
+	patternDictionary = "dictionary"
+	patternSpatial    = "spatial"
+	patternRepeat     = "repeat"
+	patternSequence   = "sequence"
+	patternRegex      = "regex"
+	patternDate       = "date"
+	patternBruteforce = "bruteforce"
+)
+
+// match
+// A part of the password, password[i:j+1], which is guessable by a pattern
+type match struct {
+	pattern string
+	i, j    int
+	token   []rune
+	guesses float64
+
+	// dictionary
+	dictionaryName string
+	matchedWord    string
+	rank           int
+	reversed       bool
+	l33t           bool
+	sub            map[rune]rune
+
+	// spatial
+	graph        string
+	turns        int
+	shiftedCount int
+
+	// repeat
+	baseToken   []rune
+	baseGuesses float64
+	repeatCount int
+
+	// sequence
+	sequenceName  string
+	sequenceSpace int
+	ascending     bool
+
+	// regex
+	regexName string
+
+	// date
+	year      int
+	separator string
+}
+
+// matcher
+// The ranked dictionaries are shared, except the user inputs which belong to each estimation
+type matcher struct {
+	names        []string
+	dictionaries map[string]map[string]int
+	// maxWordLength is the length in runes of the longest word of the dictionaries
+	maxWordLength int
+}
+
+func newMatcher(userInputs []string) *matcher {
+	m := &matcher{
+		dictionaries:  make(map[string]map[string]int, len(rankedDictionaries)+1),
+		maxWordLength: rankedMaxWordLength,
+	}
+	for name, d := range rankedDictionaries {
+		m.dictionaries[name] = d
+	}
+	userDict := make(map[string]int)
+	rank := 1
+	for _, in := range userInputs {
+		for _, w := range strings.Fields(strings.ToLower(in)) {
+			if _, ok := userDict[w]; !ok {
+				userDict[w] = rank
+				if l := utf8.RuneCountInString(w); l > m.maxWordLength {
+					m.maxWordLength = l
+				}
+				rank++
+			}
+		}
+	}
+	m.dictionaries[dictUserInputs] = userDict
+	for name := range m.dictionaries {
+		m.names = append(m.names, name)
+	}
+	// sorted, so the estimation does not depend on the order of the map
+	sort.Strings(m.names)
+	return m
+}
+
+// omnimatch returns the matches of all the patterns, sorted by their positions
+func (m *matcher) omnimatch(password []rune) []*match {
+	var matches []*match
+	matches = append(matches, m.dictionaryMatch(password)...)
+	matches = append(matches, m.reverseDictionaryMatch(password)...)
+	matches = append(matches, m.l33tMatch(password)...)
+	matches = append(matches, spatialMatch(password)...)
+	matches = append(matches, m.repeatMatch(password)...)
+	matches = append(matches, sequenceMatch(password)...)
+	matches = append(matches, regexMatch(password)...)
+	matches = append(matches, dateMatch(password)...)
+	sortMatches(matches)
+	return matches
+}
+
+func sortMatches(matches []*match) {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+}
+
+// dictionaryMatch looks every substring up which is not longer than the longest word, the substrings
+// are sliced from the lowered password
+func (m *matcher) dictionaryMatch(password []rune) []*match {
+	var matches []*match
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		return nil
+	}
+	s := string(lower)
+	// offsets[i] is the offset of the rune i in s
+	offsets := make([]int, 0, len(lower)+1)
+	for off := range s {
+		offsets = append(offsets, off)
+	}
+	offsets = append(offsets, len(s))
+	for _, name := range m.names {
+		dict := m.dictionaries[name]
+		for i := range lower {
+			for j := i; j < len(lower) && j-i < m.maxWordLength; j++ {
+				word := s[offsets[i]:offsets[j+1]]
+				rank, ok := dict[word]
+				if !ok {
+					continue
+				}
+				matches = append(matches, &match{
+					pattern:        patternDictionary,
+					i:              i,
+					j:              j,
+					token:          password[i : j+1],
+					dictionaryName: name,
+					matchedWord:    word,
+					rank:           rank,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+func (m *matcher) reverseDictionaryMatch(password []rune) []*match {
+	n := len(password)
+	reversed := make([]rune, n)
+	for i, r := range password {
+		reversed[n-1-i] = r
+	}
+	matches := m.dictionaryMatch(reversed)
+	for _, match := range matches {
+		match.token = reverseRunes(match.token)
+		match.reversed = true
+		match.i, match.j = n-1-match.j, n-1-match.i
+	}
+	return matches
+}
+
+func reverseRunes(in []rune) []rune {
+	out := make([]rune, len(in))
+	for i, r := range in {
+		out[len(in)-1-i] = r
+	}
+	return out
+}
+
+// l33tTable are the common substitutions of the letters
+var l33tTable = map[rune][]rune{
+	'a': []rune("4@"),
+	'b': []rune("8"),
+	'c': []rune("({[<"),
+	'e': []rune("3"),
+	'g': []rune("69"),
+	'i': []rune("1!|"),
+	'l': []rune("1|7"),
+	'o': []rune("0"),
+	's': []rune("$5"),
+	't': []rune("+7"),
+	'x': []rune("%"),
+	'z': []rune("2"),
+}
+
+// maxL33tSubs bounds the number of substitution tables which are tried
+const maxL33tSubs = 64
+
+// l33tSubs enumerates the substitution tables of the l33t characters in the password, a
+// character like '1' could stand for either 'i' or 'l'
+func l33tSubs(password []rune) []map[rune]rune {
+	candidates := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, s := range subs {
+			for _, r := range password {
+				if r == s {
+					candidates[s] = append(candidates[s], letter)
+					break
+				}
+			}
+		}
+	}
+	keys := make([]rune, 0, len(candidates))
+	for k, letters := range candidates {
+		keys = append(keys, k)
+		sort.Slice(letters, func(a, b int) bool { return letters[a] < letters[b] })
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+
+	tables := []map[rune]rune{{}}
+	for _, k := range keys {
+		var next []map[rune]rune
+		for _, t := range tables {
+			for _, letter := range candidates[k] {
+				nt := make(map[rune]rune, len(t)+1)
+				for kk, vv := range t {
+					nt[kk] = vv
+				}
+				nt[k] = letter
+				next = append(next, nt)
+				if len(next) >= maxL33tSubs {
+					break
+				}
+			}
+		}
+		tables = next
+	}
+	if len(tables) == 1 && len(tables[0]) == 0 {
+		return nil
+	}
+	return tables
+}
+
+func (m *matcher) l33tMatch(password []rune) []*match {
+	var matches []*match
+	seen := make(map[string]bool)
+	for _, sub := range l33tSubs(password) {
+		subbed := make([]rune, len(password))
+		for i, r := range password {
+			if l, ok := sub[r]; ok {
+				subbed[i] = l
+			} else {
+				subbed[i] = r
+			}
+		}
+		for _, match := range m.dictionaryMatch(subbed) {
+			token := password[match.i : match.j+1]
+			// only the matches which have a substitution and are longer than a single character
+			matchSub := make(map[rune]rune)
+			for _, r := range token {
+				if l, ok := sub[r]; ok {
+					matchSub[r] = l
+				}
+			}
+			if len(matchSub) == 0 || len(token) <= 1 {
+				continue
+			}
+			key := strconv.Itoa(match.i) + ":" + strconv.Itoa(match.j) + ":" + match.dictionaryName + ":" + match.matchedWord
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			match.token = token
+			match.l33t = true
+			match.sub = matchSub
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// shiftedChars are typed with the shift key on qwerty
+const shiftedChars = "~!@#$%^&*()_+QWERTYUIOPASDFGHJKLZXCVBNM{}|:\"<>?"
+
+func spatialMatch(password []rune) []*match {
+	var matches []*match
+	for _, name := range []string{graphQwerty, graphKeypad} {
+		matches = append(matches, spatialMatchHelper(password, name, adjacencyGraphs[name])...)
+	}
+	return matches
+}
+
+func spatialMatchHelper(password []rune, name string, graph map[rune][]string) []*match {
+	var matches []*match
+	i := 0
+	for i < len(password)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shiftedCount := 0
+		if name == graphQwerty && strings.ContainsRune(shiftedChars, password[i]) {
+			shiftedCount = 1
+		}
+		for {
+			found := false
+			if j < len(password) {
+				cur := password[j]
+				for direction, adj := range graph[password[j-1]] {
+					idx := strings.IndexRune(adj, cur)
+					if adj == "" || idx < 0 {
+						continue
+					}
+					found = true
+					if idx > 0 {
+						shiftedCount++
+					}
+					if lastDirection != direction {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+			if found {
+				j++
+				continue
+			}
+			// only the patterns of 3 or more keys
+			if j-i > 2 {
+				matches = append(matches, &match{
+					pattern:      patternSpatial,
+					i:            i,
+					j:            j - 1,
+					token:        password[i:j],
+					graph:        name,
+					turns:        turns,
+					shiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+// repeatMatch finds the longest repeats of a base token, e.g. "abcabcabc". The repeats of every base
+// at i are read from the Z-array of password[i:], so each position takes linear time.
+func (m *matcher) repeatMatch(password []rune) []*match {
+	var matches []*match
+	n := len(password)
+	for i := 0; i < n-1; {
+		// z[b] is the length of the prefix of password[i:] which repeats at i+b, a base of b runes is
+		// repeated (b+z[b])/b times
+		z := zArray(password[i:])
+		bestSpan, bestBase := 0, 0
+		for b := 1; 2*b <= len(z); b++ {
+			if z[b] < b {
+				continue
+			}
+			if span := (b + z[b]) / b * b; span > bestSpan {
+				bestSpan, bestBase = span, b
+			}
+		}
+		if bestSpan == 0 {
+			i++
+			continue
+		}
+		base := password[i : i+bestBase]
+		baseGuesses, _ := mostGuessableMatchSequence(base, m.omnimatch(base))
+		matches = append(matches, &match{
+			pattern:     patternRepeat,
+			i:           i,
+			j:           i + bestSpan - 1,
+			token:       password[i : i+bestSpan],
+			baseToken:   base,
+			baseGuesses: baseGuesses,
+			repeatCount: bestSpan / bestBase,
+		})
+		i += bestSpan
+	}
+	return matches
+}
+
+// zArray returns the length of the longest common prefix of s and s[k:] for every k
+func zArray(s []rune) []int {
+	n := len(s)
+	z := make([]int, n)
+	if n > 0 {
+		z[0] = n
+	}
+	for k, l, r := 1, 0, 0; k < n; k++ {
+		if k < r {
+			z[k] = z[k-l]
+			if z[k] > r-k {
+				z[k] = r - k
+			}
+		}
+		for k+z[k] < n && s[z[k]] == s[k+z[k]] {
+			z[k]++
+		}
+		if k+z[k] > r {
+			l, r = k, k+z[k]
+		}
+	}
+	return z
+}
+
+// maxSequenceDelta is the largest step between the characters of a sequence, e.g. "aceg" or "2468"
+const maxSequenceDelta = 5
+
+func sequenceMatch(password []rune) []*match {
+	if len(password) == 1 {
+		return nil
+	}
+	var matches []*match
+	add := func(i, j, delta int) {
+		absDelta := delta
+		if absDelta < 0 {
+			absDelta = -absDelta
+		}
+		if (j-i > 1 || absDelta == 1) && absDelta > 0 && absDelta <= maxSequenceDelta {
+			token := password[i : j+1]
+			name, space := "unicode", 26
+			switch s := string(token); {
+			case isAll(s, unicode.IsLower):
+				name, space = "lower", 26
+			case isAll(s, unicode.IsUpper):
+				name, space = "upper", 26
+			case isAll(s, unicode.IsDigit):
+				name, space = "digits", 10
+			}
+			matches = append(matches, &match{
+				pattern:       patternSequence,
+				i:             i,
+				j:             j,
+				token:         token,
+				sequenceName:  name,
+				sequenceSpace: space,
+				ascending:     delta > 0,
+			})
+		}
+	}
+
+	i := 0
+	lastDelta, hasLast := 0, false
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if !hasLast {
+			lastDelta, hasLast = delta, true
+		}
+		if delta == lastDelta {
+			continue
+		}
+		j := k - 1
+		add(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	add(i, len(password)-1, lastDelta)
+	return matches
+}
+
+func isAll(s string, f func(rune) bool) bool {
+	for _, r := range s {
+		if !f(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var recentYearRx = regexp.MustCompile(`19\d\d|20\d\d`)
+
+func regexMatch(password []rune) []*match {
+	var matches []*match
+	s := string(password)
+	for _, loc := range recentYearRx.FindAllStringIndex(s, -1) {
+		i := len([]rune(s[:loc[0]]))
+		j := i + len([]rune(s[loc[0]:loc[1]])) - 1
+		matches = append(matches, &match{
+			pattern:   patternRegex,
+			i:         i,
+			j:         j,
+			token:     password[i : j+1],
+			regexName: "recent_year",
+		})
+	}
+	return matches
+}
+
+const (
+	dateMinYear = 1000
+	dateMaxYear = 2050
+)
+
+// dateSplits are the positions which a date with no separator is split at by its length
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+var (
+	dateMaybeNoSeparatorRx = regexp.MustCompile(`^\d{4,8}$`)
+	dateWithSeparatorRx    = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+)
+
+// dateMatch finds the dates with or without separators, e.g. "13.5.1990" or "19900513"
+func dateMatch(password []rune) []*match {
+	var matches []*match
+	n := len(password)
+
+	for i := 0; i+3 < n; i++ {
+		for j := i + 3; j <= i+7 && j < n; j++ {
+			token := string(password[i : j+1])
+			if !dateMaybeNoSeparatorRx.MatchString(token) {
+				continue
+			}
+			bestYear, found := 0, false
+			for _, split := range dateSplits[len(token)] {
+				ints := [3]int{atoi(token[:split[0]]), atoi(token[split[0]:split[1]]), atoi(token[split[1]:])}
+				if year, ok := mapIntsToYear(ints); ok {
+					if !found || absInt(year-referenceYear) < absInt(bestYear-referenceYear) {
+						bestYear, found = year, true
+					}
+				}
+			}
+			if found {
+				matches = append(matches, &match{
+					pattern: patternDate,
+					i:       i,
+					j:       j,
+					token:   password[i : j+1],
+					year:    bestYear,
+				})
+			}
+		}
+	}
+
+	for i := 0; i+5 < n; i++ {
+		for j := i + 5; j <= i+9 && j < n; j++ {
+			token := string(password[i : j+1])
+			parts := dateWithSeparatorRx.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+			if year, ok := mapIntsToYear([3]int{atoi(parts[1]), atoi(parts[3]), atoi(parts[5])}); ok {
+				matches = append(matches, &match{
+					pattern:   patternDate,
+					i:         i,
+					j:         j,
+					token:     password[i : j+1],
+					year:      year,
+					separator: parts[2],
+				})
+			}
+		}
+	}
+
+	// the dates which are part of a longer date are dropped
+	filtered := matches[:0]
+	for _, m := range matches {
+		sub := false
+		for _, other := range matches {
+			if m != other && other.i <= m.i && other.j >= m.j {
+				sub = true
+				break
+			}
+		}
+		if !sub {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// mapIntsToYear returns the year of the day, month and year in any order if they make a date
+func mapIntsToYear(ints [3]int) (int, bool) {
+	if ints[1] > 31 || ints[1] <= 0 {
+		return 0, false
+	}
+	over12, over31, under1 := 0, 0, 0
+	for _, x := range ints {
+		if (x > 99 && x < dateMinYear) || x > dateMaxYear {
+			return 0, false
+		}
+		if x > 31 {
+			over31++
+		}
+		if x > 12 {
+			over12++
+		}
+		if x <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, false
+	}
+
+	candidates := [][3]int{{ints[2], ints[0], ints[1]}, {ints[0], ints[1], ints[2]}}
+	for _, c := range candidates {
+		if c[0] >= dateMinYear && c[0] <= dateMaxYear {
+			if isDayMonth(c[1], c[2]) {
+				return c[0], true
+			}
+			return 0, false
+		}
+	}
+	for _, c := range candidates {
+		if isDayMonth(c[1], c[2]) {
+			return twoToFourDigitYear(c[0]), true
+		}
+	}
+	return 0, false
+}
+
+func isDayMonth(a, b int) bool {
+	return (a >= 1 && a <= 31 && b >= 1 && b <= 12) || (b >= 1 && b <= 31 && a >= 1 && a <= 12)
+}
+
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+func atoi(s string) int {
+	x, _ := strconv.Atoi(s)
+	return x
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func newBruteforceMatch(password []rune, i, j int) *match {
+	return &match{
+		pattern: patternBruteforce,
+		i:       i,
+		j:       j,
+		token:   password[i : j+1],
+	}
+}
@@ -0,0 +1,225 @@
+package strength
+
+import (
+	"math"
+	"strconv"
+)
+
+// Scores of the estimation, a password should be at least ScoreSafe
+const (
+	ScoreTooGuessable = iota
+	ScoreVeryGuessable
+	ScoreSomewhatGuessable
+	ScoreSafe
+	ScoreVeryUnguessable
+)
+
+// MaxPasswordLength is the number of runes which are estimated, the rest of a longer password is not
+// matched since the matching takes quadratic time
+const MaxPasswordLength = 100
+
+// minGuessesBeforeGrowingSequence keeps the sequences of many short matches from being underestimated
+const minGuessesBeforeGrowingSequence = 10000
+
+// Result
+// Guesses is the estimated number of guesses to crack the password, CrackTimes are in seconds
+type Result struct {
+	Score        int
+	Guesses      float64
+	GuessesLog10 float64
+	CrackTimes   CrackTimes
+	Warning      string
+	Suggestions  []string
+	Patterns     []string
+}
+
+// CrackTimes
+type CrackTimes struct {
+	OnlineThrottling   float64
+	OnlineNoThrottling float64
+	OfflineSlowHashing float64
+	OfflineFastHashing float64
+	Display            string
+}
+
+// Estimate estimates the strength of the password, userInputs are the words which are known to be
+// related to the user, e.g. the names and the phone number. Only the first MaxPasswordLength runes
+// are estimated.
+func Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	if len(runes) > MaxPasswordLength {
+		runes = runes[:MaxPasswordLength]
+	}
+	m := newMatcher(userInputs)
+	matches := m.omnimatch(runes)
+	guesses, sequence := mostGuessableMatchSequence(runes, matches)
+
+	res := Result{
+		Guesses:      guesses,
+		GuessesLog10: math.Log10(guesses),
+		Score:        guessesToScore(guesses),
+	}
+	res.CrackTimes = crackTimes(guesses)
+	res.Warning, res.Suggestions = feedback(res.Score, sequence)
+	for _, match := range sequence {
+		res.Patterns = append(res.Patterns, match.pattern)
+	}
+	return res
+}
+
+// mostGuessableMatchSequence finds the sequence of non-overlapping matches covering the password which
+// needs the least guesses, the gaps are filled by bruteforce matches. A sequence of l matches takes
+// l! * (product of their guesses) guesses, since the attacker does not know the order of the patterns.
+func mostGuessableMatchSequence(password []rune, matches []*match) (float64, []*match) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matchesByJ := make([][]*match, n)
+	for _, m := range matches {
+		matchesByJ[m.j] = append(matchesByJ[m.j], m)
+	}
+
+	type step struct {
+		m  *match
+		pi float64
+		g  float64
+	}
+	// optimal[k][l] is the best sequence of l matches covering password[0:k+1]
+	optimal := make([]map[int]step, n)
+	for k := range optimal {
+		optimal[k] = make(map[int]step)
+	}
+
+	update := func(m *match, l int) {
+		k := m.j
+		pi := estimateGuesses(m, password)
+		if l > 1 {
+			pi *= optimal[m.i-1][l-1].pi
+		}
+		g := factorial(l) * pi
+		g += math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		for cl, cs := range optimal[k] {
+			if cl <= l && cs.g <= g {
+				return
+			}
+		}
+		optimal[k][l] = step{m: m, pi: pi, g: g}
+	}
+
+	bruteforceUpdate := func(k int) {
+		update(newBruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			m := newBruteforceMatch(password, i, k)
+			for l, last := range optimal[i-1] {
+				// two adjacent bruteforce matches are never better than a single one
+				if last.m.pattern == patternBruteforce {
+					continue
+				}
+				update(m, l+1)
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range matchesByJ[k] {
+			if m.i > 0 {
+				for l := range optimal[m.i-1] {
+					update(m, l+1)
+				}
+			} else {
+				update(m, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	bestL, bestG := 0, math.Inf(1)
+	for l, s := range optimal[n-1] {
+		if s.g < bestG || (s.g == bestG && l < bestL) {
+			bestL, bestG = l, s.g
+		}
+	}
+	sequence := make([]*match, bestL)
+	for k, l := n-1, bestL; k >= 0 && l > 0; l-- {
+		m := optimal[k][l].m
+		sequence[l-1] = m
+		k = m.i - 1
+	}
+	return bestG, sequence
+}
+
+func guessesToScore(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return ScoreTooGuessable
+	case guesses < 1e6+delta:
+		return ScoreVeryGuessable
+	case guesses < 1e8+delta:
+		return ScoreSomewhatGuessable
+	case guesses < 1e10+delta:
+		return ScoreSafe
+	default:
+		return ScoreVeryUnguessable
+	}
+}
+
+// crackTimes of the attack scenarios, the password hash of SRP is a slow hash
+func crackTimes(guesses float64) CrackTimes {
+	ct := CrackTimes{
+		OnlineThrottling:   guesses / (100.0 / 3600),
+		OnlineNoThrottling: guesses / 10,
+		OfflineSlowHashing: guesses / 1e4,
+		OfflineFastHashing: guesses / 1e10,
+	}
+	ct.Display = displayTime(ct.OfflineSlowHashing)
+	return ct
+}
+
+func displayTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+	var (
+		base float64
+		unit string
+	)
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		base, unit = seconds, "second"
+	case seconds < hour:
+		base, unit = seconds/minute, "minute"
+	case seconds < day:
+		base, unit = seconds/hour, "hour"
+	case seconds < month:
+		base, unit = seconds/day, "day"
+	case seconds < year:
+		base, unit = seconds/month, "month"
+	case seconds < century:
+		base, unit = seconds/year, "year"
+	default:
+		return "centuries"
+	}
+	n := int64(math.Round(base))
+	if n != 1 {
+		unit += "s"
+	}
+	return strconv.FormatInt(n, 10) + " " + unit
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
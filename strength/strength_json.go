@@ -0,0 +1,103 @@
+package strength
+
+import (
+	"git.ronaksoft.com/river/web-wasm/jsonx"
+)
+
+// MarshalJSON implements json.Marshaler
+func (v Result) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	w.ObjectStart()
+	w.Field("Score")
+	w.Int64(int64(v.Score))
+	w.Field("Guesses")
+	w.Float64(v.Guesses)
+	w.Field("GuessesLog10")
+	w.Float64(v.GuessesLog10)
+	w.Field("CrackTimes")
+	v.CrackTimes.writeJSON(&w)
+	w.Field("Warning")
+	w.String(v.Warning)
+	w.Field("Suggestions")
+	w.Array(v.Suggestions == nil, len(v.Suggestions), func(i int) { w.String(v.Suggestions[i]) })
+	w.Field("Patterns")
+	w.Array(v.Patterns == nil, len(v.Patterns), func(i int) { w.String(v.Patterns[i]) })
+	w.ObjectEnd()
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *Result) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	l.Object(func(name string) {
+		switch name {
+		case "Score":
+			v.Score = l.Int()
+		case "Guesses":
+			v.Guesses = l.Float64()
+		case "GuessesLog10":
+			v.GuessesLog10 = l.Float64()
+		case "CrackTimes":
+			v.CrackTimes.readJSON(l)
+		case "Warning":
+			v.Warning = l.String()
+		case "Suggestions":
+			v.Suggestions = []string{}
+			l.Array(func() { v.Suggestions = append(v.Suggestions, l.String()) })
+		case "Patterns":
+			v.Patterns = []string{}
+			l.Array(func() { v.Patterns = append(v.Patterns, l.String()) })
+		default:
+			l.Skip()
+		}
+	})
+	return l.Done()
+}
+
+// MarshalJSON implements json.Marshaler
+func (v CrackTimes) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{}
+	v.writeJSON(&w)
+	return w.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *CrackTimes) UnmarshalJSON(data []byte) error {
+	l := jsonx.NewLexer(data)
+	v.readJSON(l)
+	return l.Done()
+}
+
+func (v CrackTimes) writeJSON(w *jsonx.Writer) {
+	w.ObjectStart()
+	w.Field("OnlineThrottling")
+	w.Float64(v.OnlineThrottling)
+	w.Field("OnlineNoThrottling")
+	w.Float64(v.OnlineNoThrottling)
+	w.Field("OfflineSlowHashing")
+	w.Float64(v.OfflineSlowHashing)
+	w.Field("OfflineFastHashing")
+	w.Float64(v.OfflineFastHashing)
+	w.Field("Display")
+	w.String(v.Display)
+	w.ObjectEnd()
+}
+
+func (v *CrackTimes) readJSON(l *jsonx.Lexer) {
+	l.Object(func(name string) {
+		switch name {
+		case "OnlineThrottling":
+			v.OnlineThrottling = l.Float64()
+		case "OnlineNoThrottling":
+			v.OnlineNoThrottling = l.Float64()
+		case "OfflineSlowHashing":
+			v.OfflineSlowHashing = l.Float64()
+		case "OfflineFastHashing":
+			v.OfflineFastHashing = l.Float64()
+		case "Display":
+			v.Display = l.String()
+		default:
+			l.Skip()
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package strength
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The scores are the ones of zxcvbn for the same passwords
+func TestEstimateScores(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+		score      int
+		patterns   string
+	}{
+		{"password", nil, ScoreTooGuessable, "dictionary"},
+		{"123456", nil, ScoreTooGuessable, "dictionary"},
+		{"P@ssw0rd", nil, ScoreTooGuessable, "dictionary"},
+		{"drowssap", nil, ScoreTooGuessable, "dictionary"},
+		{"zxcvbnm", nil, ScoreTooGuessable, "dictionary"},
+		{"abcabcabcabc", nil, ScoreTooGuessable, "repeat"},
+		{"aaaaaaaaaaaaaaa", nil, ScoreTooGuessable, "repeat"},
+		{"jennifer1990", nil, ScoreVeryGuessable, "dictionary regex"},
+		{"Tr0ub4dour&3", nil, ScoreSomewhatGuessable, "dictionary bruteforce"},
+		{"alireza1370", nil, ScoreSafe, "dictionary dictionary date"},
+		{"x7#Kp2!mQ9zL", nil, ScoreVeryUnguessable, "bruteforce"},
+		{"correcthorsebatterystaple", nil, ScoreVeryUnguessable, "dictionary dictionary dictionary dictionary"},
+		{"rWibMFACxAUGZmxhVncy", nil, ScoreVeryUnguessable, "bruteforce"},
+		{"rwibmfacxaugzmxhvncy", []string{"rWibMFACxAUGZmxhVncy"}, ScoreTooGuessable, "dictionary"},
+	}
+	for _, tt := range tests {
+		res := Estimate(tt.password, tt.userInputs...)
+		if res.Score != tt.score {
+			t.Errorf("%q: score %d, expected %d (guesses 10^%.2f)", tt.password, res.Score, tt.score, res.GuessesLog10)
+		}
+		if patterns := strings.Join(res.Patterns, " "); patterns != tt.patterns {
+			t.Errorf("%q: patterns %q, expected %q", tt.password, patterns, tt.patterns)
+		}
+	}
+}
+
+func TestEstimateFeedback(t *testing.T) {
+	res := Estimate("password")
+	if res.Warning == "" || len(res.Suggestions) == 0 {
+		t.Fatalf("no feedback of a common password: %+v", res)
+	}
+	if res = Estimate("x7#Kp2!mQ9zL"); res.Warning != "" {
+		t.Fatalf("warning %q of a strong password", res.Warning)
+	}
+}
+
+func TestRepeatMatch(t *testing.T) {
+	tests := []struct {
+		password string
+		base     string
+		count    int
+		i, j     int
+	}{
+		{"abcabcabc", "abc", 3, 0, 8},
+		{"aaaa", "a", 4, 0, 3},
+		{"xyzabab!", "ab", 2, 3, 6},
+		{"abababa", "ab", 3, 0, 5},
+	}
+	m := newMatcher(nil)
+	for _, tt := range tests {
+		matches := m.repeatMatch([]rune(tt.password))
+		if len(matches) != 1 {
+			t.Errorf("%q: %d repeats, expected 1", tt.password, len(matches))
+			continue
+		}
+		r := matches[0]
+		if string(r.baseToken) != tt.base || r.repeatCount != tt.count || r.i != tt.i || r.j != tt.j {
+			t.Errorf("%q: repeat of %q %d times at [%d, %d], expected %q %d times at [%d, %d]",
+				tt.password, string(r.baseToken), r.repeatCount, r.i, r.j, tt.base, tt.count, tt.i, tt.j)
+		}
+	}
+	if matches := m.repeatMatch([]rune("abcdef")); len(matches) != 0 {
+		t.Errorf("repeats of %q", "abcdef")
+	}
+}
+
+// Only MaxPasswordLength runes are estimated, a long password is estimated as its prefix and in time
+// for the JS thread
+func TestEstimateLongPassword(t *testing.T) {
+	for _, p := range []string{
+		strings.Repeat("a", 10*MaxPasswordLength),
+		strings.Repeat("abcdefghij1234567890", MaxPasswordLength),
+		strings.Repeat("x7#Kp2!mQ9zL", MaxPasswordLength),
+	} {
+		start := time.Now()
+		res := Estimate(p)
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%d runes are estimated in %v", len(p), d)
+		}
+		if prefix := Estimate(p[:MaxPasswordLength]); res.Guesses != prefix.Guesses {
+			t.Errorf("%d runes: %v guesses, expected %v of the first %d runes",
+				len(p), res.Guesses, prefix.Guesses, MaxPasswordLength)
+		}
+	}
+}
+
+func BenchmarkEstimate(b *testing.B) {
+	p := strings.Repeat("abcdefghij1234567890", 5)
+	for i := 0; i < b.N; i++ {
+		Estimate(p)
+	}
+}
@@ -0,0 +1,56 @@
+package strength
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Names of the ranked dictionaries
+const (
+	dictPasswords  = "passwords"
+	dictEnglish    = "english"
+	dictNames      = "names"
+	dictUserInputs = "user_inputs"
+)
+
+// rankedDictionaries maps the words to their ranks, the most common word has the rank 1. The lists are
+// in wordlists_data.go, which is written by wordlists_gen.go.
+var rankedDictionaries = map[string]map[string]int{
+	dictPasswords: buildRankedDictionary(passwordList),
+	dictEnglish:   buildRankedDictionary(englishList),
+	dictNames:     buildRankedDictionary(maleNameList, femaleNameList, surnameList, localNameList),
+}
+
+// rankedMaxWordLength is the length in runes of the longest word of the ranked dictionaries
+var rankedMaxWordLength = maxWordLength(rankedDictionaries)
+
+func maxWordLength(dictionaries map[string]map[string]int) int {
+	max := 0
+	for _, d := range dictionaries {
+		for w := range d {
+			if l := utf8.RuneCountInString(w); l > max {
+				max = l
+			}
+		}
+	}
+	return max
+}
+
+// buildRankedDictionary ranks the words of each list by their position, a word of many lists keeps
+// its best rank
+func buildRankedDictionary(lists ...string) map[string]int {
+	d := make(map[string]int)
+	for _, list := range lists {
+		for i, w := range strings.Fields(list) {
+			if rank, ok := d[w]; !ok || i+1 < rank {
+				d[w] = i + 1
+			}
+		}
+	}
+	return d
+}
+
+// localNameList are the common Persian names, which are not in the lists of zxcvbn
+const localNameList = `
+ali mohammad hassan hossein reza mehdi amir sara maryam fatemeh zahra
+`
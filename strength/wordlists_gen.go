@@ -0,0 +1,85 @@
+//go:build ignore
+// +build ignore
+
+// wordlists_gen writes wordlists_data.go from the frequency lists of zxcvbn-go
+// (github.com/ccojocar/zxcvbn-go, MIT license, Copyright (c) Nathan Button):
+//
+//	go run wordlists_gen.go <zxcvbn-go>/data/data
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lists are the constants which are written, the first max words of each file are kept
+var lists = []struct {
+	name string
+	file string
+	max  int
+}{
+	{"passwordList", "Passwords.json", 0},
+	{"englishList", "English.json", 30000},
+	{"maleNameList", "MaleNames.json", 0},
+	{"femaleNameList", "FemaleNames.json", 0},
+	{"surnameList", "Surnames.json", 10000},
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run wordlists_gen.go <zxcvbn-go>/data/data")
+	}
+	var b bytes.Buffer
+	b.WriteString("// Code generated by wordlists_gen.go; DO NOT EDIT.\n\n")
+	b.WriteString("// The lists are the frequency lists of zxcvbn-go (github.com/ccojocar/zxcvbn-go),\n")
+	b.WriteString("// MIT license, Copyright (c) Nathan Button\n\n")
+	b.WriteString("package strength\n\nconst (\n")
+	for _, l := range lists {
+		data, err := ioutil.ReadFile(filepath.Join(os.Args[1], l.file))
+		if err != nil {
+			log.Fatal(err)
+		}
+		var v struct{ List []string }
+		if err = json.Unmarshal(data, &v); err != nil {
+			log.Fatalf("%s: %v", l.file, err)
+		}
+		words := v.List
+		if l.max > 0 && len(words) > l.max {
+			words = words[:l.max]
+		}
+		fmt.Fprintf(&b, "\t%s = `\n", l.name)
+		line := 0
+		for _, w := range words {
+			w = strings.ToLower(w)
+			if strings.ContainsAny(w, " \t\n`") {
+				continue
+			}
+			if line > 0 && line+1+len(w) > 100 {
+				b.WriteByte('\n')
+				line = 0
+			}
+			if line > 0 {
+				b.WriteByte(' ')
+				line++
+			}
+			b.WriteString(w)
+			line += len(w)
+		}
+		b.WriteString("\n`\n")
+	}
+	b.WriteString(")\n")
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("wordlists_data.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
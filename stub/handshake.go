@@ -0,0 +1,201 @@
+package stub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"time"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// pqBits is the size of the primes of PQ, the clients factorize it in a fraction of a second
+const pqBits = 31
+
+// pendingAuth
+// The handshake between InitConnect and InitCompleteAuth, by the nonce of the client
+type pendingAuth struct {
+	serverNonce uint64
+	p, q        uint64
+	fingerPrint int64
+}
+
+// handleUnencrypted replies to the handshake and the other requests which are sent without an auth key
+func (s *Server) handleUnencrypted(req *msg.MessageEnvelope) ([]byte, error) {
+	var (
+		res *msg.MessageEnvelope
+		err error
+	)
+	switch req.Constructor {
+	case msg.C_InitConnect:
+		res, err = s.initConnect(req.Message)
+	case msg.C_InitCompleteAuth:
+		res, err = s.initCompleteAuth(req.Message)
+	case msg.C_SystemGetServerTime:
+		s.mtx.Lock()
+		handler := s.handler
+		s.mtx.Unlock()
+		if res = handler(0, req); res == nil {
+			return nil, nil
+		}
+	default:
+		err = _errors.ErrNoAuthKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.RequestID = req.RequestID
+	return res.Marshal()
+}
+
+func (s *Server) initConnect(data []byte) (*msg.MessageEnvelope, error) {
+	req := &msg.InitConnect{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	// X25519 is selected whenever the client supports it
+	auth := &pendingAuth{
+		serverNonce: utils.RandomUint64(),
+		fingerPrint: DHFingerPrint,
+	}
+	for _, fp := range req.ECDHFingerPrints {
+		if int64(fp) == ECDHFingerPrint {
+			auth.fingerPrint = ECDHFingerPrint
+		}
+	}
+	p, err := rand.Prime(rand.Reader, pqBits)
+	if err != nil {
+		return nil, err
+	}
+	q, err := rand.Prime(rand.Reader, pqBits)
+	if err != nil {
+		return nil, err
+	}
+	auth.p, auth.q = p.Uint64(), q.Uint64()
+	if auth.p > auth.q {
+		auth.p, auth.q = auth.q, auth.p
+	}
+
+	s.mtx.Lock()
+	s.pending[req.ClientNonce] = auth
+	s.mtx.Unlock()
+
+	return envelope(msg.C_InitResponse, &msg.InitResponse{
+		ClientNonce:          req.ClientNonce,
+		ServerNonce:          auth.serverNonce,
+		RSAPubKeyFingerPrint: uint64(RSAFingerPrint),
+		DHGroupFingerPrint:   uint64(auth.fingerPrint),
+		PQ:                   auth.p * auth.q,
+		ServerTimestamp:      time.Now().Unix(),
+	}), nil
+}
+
+func (s *Server) initCompleteAuth(data []byte) (*msg.MessageEnvelope, error) {
+	req := &msg.InitCompleteAuth{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	s.mtx.Lock()
+	auth := s.pending[req.ClientNonce]
+	delete(s.pending, req.ClientNonce)
+	s.mtx.Unlock()
+	if auth == nil || auth.serverNonce != req.ServerNonce {
+		return nil, _errors.ErrNonceMismatch
+	}
+
+	res := &msg.InitAuthCompleted{
+		ClientNonce: req.ClientNonce,
+		ServerNonce: req.ServerNonce,
+		Status:      msg.InitAuthCompleted_FAIL,
+	}
+	if req.P != auth.p || req.Q != auth.q {
+		return envelope(msg.C_InitAuthCompleted, res), nil
+	}
+	decrypted, err := s.decryptPayload(req.EncryptedPayload)
+	if err != nil {
+		return envelope(msg.C_InitAuthCompleted, res), nil
+	}
+	internal := &msg.InitCompleteAuthInternal{}
+	if err = internal.Unmarshal(decrypted); err != nil {
+		return envelope(msg.C_InitAuthCompleted, res), nil
+	}
+
+	var authKey []byte
+	if auth.fingerPrint == ECDHFingerPrint {
+		res.ServerDHPubKey, authKey, err = x25519AuthKey(req.ClientNonce, req.ServerNonce, req.ClientDHPubKey)
+	} else {
+		res.ServerDHPubKey, authKey, err = s.dhAuthKey(req.ClientDHPubKey)
+	}
+	if err != nil {
+		return envelope(msg.C_InitAuthCompleted, res), nil
+	}
+
+	authID, authKeyHash := authIDOf(authKey)
+	secret := make([]byte, 0, len(internal.SecretNonce)+9)
+	secret = append(secret, internal.SecretNonce...)
+	secret = append(secret, byte(msg.InitAuthCompleted_OK))
+	secret = append(secret, authKeyHash[:8]...)
+	secretHash, _ := utils.Sha256(secret)
+	res.Status = msg.InitAuthCompleted_OK
+	res.SecretHash = binary.LittleEndian.Uint64(secretHash[24:32])
+
+	s.mtx.Lock()
+	s.authKeys[authID] = authKey
+	s.mtx.Unlock()
+	return envelope(msg.C_InitAuthCompleted, res), nil
+}
+
+// decryptPayload decrypts the encrypted payload of InitCompleteAuth by the padding of the RSA key
+func (s *Server) decryptPayload(encrypted []byte) ([]byte, error) {
+	if s.padding == river_conn.PaddingPKCS1v15 {
+		return rsa.DecryptPKCS1v15(rand.Reader, s.rsaKey, encrypted)
+	}
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, s.rsaKey, encrypted, nil)
+}
+
+// dhAuthKey returns the public key of the server and the auth key g^ab of the DH group
+func (s *Server) dhAuthKey(clientPubKey []byte) (serverPubKey, authKey []byte, err error) {
+	ga := big.NewInt(0).SetBytes(clientPubKey)
+	if err = utils.CheckDHPublicKey(s.dhPrime, ga); err != nil {
+		return
+	}
+	b, err := rand.Int(rand.Reader, s.dhPrime)
+	if err != nil {
+		return
+	}
+	serverPubKey = big.NewInt(0).Exp(big.NewInt(2), b, s.dhPrime).Bytes()
+	authKey, err = utils.FixedBytes(big.NewInt(0).Exp(ga, b, s.dhPrime), 256)
+	return
+}
+
+// x25519AuthKey returns the public key of the server and the auth key which is expanded from the X25519
+// secret by HKDF-SHA512 salted with the nonces
+func x25519AuthKey(clientNonce, serverNonce uint64, clientPubKey []byte) (serverPubKey, authKey []byte, err error) {
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if _, err = io.ReadFull(rand.Reader, privateKey); err != nil {
+		return
+	}
+	if serverPubKey, err = curve25519.X25519(privateKey, curve25519.Basepoint); err != nil {
+		return
+	}
+	secret, err := curve25519.X25519(privateKey, clientPubKey)
+	if err != nil {
+		return
+	}
+	salt := make([]byte, 16)
+	binary.LittleEndian.PutUint64(salt, clientNonce)
+	binary.LittleEndian.PutUint64(salt[8:], serverNonce)
+	authKey = make([]byte, 256)
+	_, err = io.ReadFull(hkdf.New(sha512.New, secret, salt, []byte("river auth key")), authKey)
+	return
+}
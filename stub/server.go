@@ -0,0 +1,306 @@
+package stub
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	river_conn "git.ronaksoft.com/river/web-wasm/connection"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"git.ronaksoft.com/river/web-wasm/msg"
+	"git.ronaksoft.com/river/web-wasm/utils"
+	"golang.org/x/net/websocket"
+)
+
+// Env is the environment of the server keys which the stub signs
+const Env = river_conn.EnvStaging
+
+// Finger prints of the keys of the stub
+const (
+	RSAFingerPrint  int64 = 1001
+	DHFingerPrint   int64 = 2001
+	ECDHFingerPrint int64 = 3001
+)
+
+// MODP2048 is the prime of the 2048 bits MODP group of RFC 3526 in hex, its generator is 2
+const MODP2048 = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DDE" +
+	"F9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7EDEE386BFB5A89" +
+	"9FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F83655D23DCA3AD961C62F35" +
+	"6208552BB9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3BE39E772C180E86039B2783A2EC07A28FB5" +
+	"C55DF06F4C52C9DE2BCBF6955817183995497CEA956AE515D2261898FA051015728E5A8AACAA68FFFFFFFFFFFFFFFF"
+
+// serverKeysTTL is how long the signed server keys of the stub are valid
+const serverKeysTTL = 24 * time.Hour
+
+// Handler replies to the requests which are sent by the authorized clients and to SystemGetServerTime,
+// which is sent without an auth key by authID 0. nil reply sends nothing.
+type Handler func(authID int64, req *msg.MessageEnvelope) (res *msg.MessageEnvelope)
+
+// Server
+// An in-process River server which speaks the handshake and the encrypted envelopes over WebSocket.
+// It is meant for the tests and for trying the clients, it keeps everything in memory.
+type Server struct {
+	rootKey    ed25519.PrivateKey
+	rsaKey     *rsa.PrivateKey
+	padding    string
+	dhPrime    *big.Int
+	serverKeys []byte
+	salt       int64
+	messageSeq int64
+
+	mtx      sync.Mutex
+	handler  Handler
+	authKeys map[int64][]byte
+	pending  map[uint64]*pendingAuth
+	conns    map[*conn]struct{}
+}
+
+// conn
+type conn struct {
+	ws        *websocket.Conn
+	sendMtx   sync.Mutex
+	authID    int64
+	sessionID int64
+}
+
+// NewServer generates the keys of the stub and signs its server keys, the RSA key is of OAEP-SHA256
+func NewServer() (*Server, error) {
+	return NewServerWithPadding(river_conn.PaddingOAEPSHA256)
+}
+
+// NewServerWithPadding is NewServer which RSA key is of the padding, e.g. PaddingPKCS1v15 of the
+// older server keys
+func NewServerWithPadding(padding string) (*Server, error) {
+	if padding != river_conn.PaddingOAEPSHA256 && padding != river_conn.PaddingPKCS1v15 {
+		return nil, _errors.ErrUnsupportedPadding
+	}
+	s := &Server{
+		padding:  padding,
+		salt:     utils.RandomInt63(),
+		authKeys: make(map[int64][]byte),
+		pending:  make(map[uint64]*pendingAuth),
+		conns:    make(map[*conn]struct{}),
+	}
+	s.handler = s.defaultHandler
+	s.dhPrime, _ = big.NewInt(0).SetString(MODP2048, 16)
+
+	var err error
+	_, s.rootKey, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := river_conn.ServerKeys{}
+	err = keys.UnmarshalJSON([]byte(`{"PublicKeys":[{"N":"` + s.rsaKey.N.String() +
+		`","FingerPrint":` + strconv.FormatInt(RSAFingerPrint, 10) +
+		`,"E":` + strconv.Itoa(s.rsaKey.E) +
+		`,"Padding":"` + padding + `"}],` +
+		`"DHGroups":[{"Prime":"` + MODP2048 + `","Gen":2,"FingerPrint":` + strconv.FormatInt(DHFingerPrint, 10) + `}],` +
+		`"ECDHGroups":[{"Curve":"X25519","FingerPrint":` + strconv.FormatInt(ECDHFingerPrint, 10) + `}]}`))
+	if err != nil {
+		return nil, err
+	}
+	bundle := river_conn.SignedServerKeys{
+		Env:       Env,
+		ExpiresAt: time.Now().Add(serverKeysTTL).Unix(),
+	}
+	if bundle.Keys, err = keys.MarshalJSON(); err != nil {
+		return nil, err
+	}
+	bundle.Sign(s.rootKey)
+	if s.serverKeys, err = bundle.MarshalJSON(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ServerKeys returns the signed server keys of the stub, which the clients load
+func (s *Server) ServerKeys() string {
+	return string(s.serverKeys)
+}
+
+// RootPublicKey returns the base64 encoded root key which the server keys are signed with
+func (s *Server) RootPublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.rootKey.Public().(ed25519.PublicKey))
+}
+
+// HandleFunc replaces the handler of the requests, the default one only replies to SystemGetServerTime
+func (s *Server) HandleFunc(h Handler) {
+	s.mtx.Lock()
+	s.handler = h
+	s.mtx.Unlock()
+}
+
+// ServeHTTP accepts the WebSocket connections of the clients
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	websocket.Server{Handler: s.serve}.ServeHTTP(w, req)
+}
+
+// Push sends the envelope, e.g. an UpdateContainer, to all the connections of the auth key
+func (s *Server) Push(authID int64, env *msg.MessageEnvelope) error {
+	s.mtx.Lock()
+	var conns []*conn
+	for c := range s.conns {
+		if c.authID == authID {
+			conns = append(conns, c)
+		}
+	}
+	s.mtx.Unlock()
+	if len(conns) == 0 {
+		return _errors.ErrNotFound
+	}
+	for _, c := range conns {
+		if err := s.sendEncrypted(c, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) serve(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	c := &conn{ws: ws}
+	s.mtx.Lock()
+	s.conns[c] = struct{}{}
+	s.mtx.Unlock()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.conns, c)
+		s.mtx.Unlock()
+		_ = ws.Close()
+	}()
+
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(ws, &frame); err != nil {
+			return
+		}
+		if err := s.handleFrame(c, frame); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handleFrame(c *conn, frame []byte) error {
+	pm := &msg.ProtoMessage{}
+	if err := pm.Unmarshal(frame); err != nil {
+		return err
+	}
+
+	if pm.AuthID == 0 {
+		env := &msg.MessageEnvelope{}
+		if err := env.Unmarshal(pm.Payload); err != nil {
+			return err
+		}
+		res, err := s.handleUnencrypted(env)
+		if err != nil || res == nil {
+			return err
+		}
+		return s.send(c, &msg.ProtoMessage{Payload: res})
+	}
+
+	s.mtx.Lock()
+	authKey := s.authKeys[pm.AuthID]
+	handler := s.handler
+	s.mtx.Unlock()
+	if authKey == nil {
+		return _errors.ErrNoAuthKey
+	}
+	plain, err := utils.Decrypt(authKey, pm.MessageKey, pm.Payload)
+	if err != nil {
+		return err
+	}
+	payload := &msg.ProtoEncryptedPayload{}
+	if err = payload.Unmarshal(plain); err != nil {
+		return err
+	}
+	if payload.Envelope == nil {
+		return nil
+	}
+	s.mtx.Lock()
+	c.authID, c.sessionID = pm.AuthID, payload.SessionID
+	s.mtx.Unlock()
+
+	res := handler(pm.AuthID, payload.Envelope)
+	if res == nil {
+		return nil
+	}
+	res.RequestID = payload.Envelope.RequestID
+	return s.sendEncrypted(c, res)
+}
+
+// defaultHandler replies to SystemGetServerTime, the other requests are answered by an error
+func (s *Server) defaultHandler(authID int64, req *msg.MessageEnvelope) *msg.MessageEnvelope {
+	switch req.Constructor {
+	case msg.C_SystemGetServerTime:
+		return envelope(msg.C_SystemServerTime, &msg.SystemServerTime{Timestamp: time.Now().Unix()})
+	default:
+		return envelope(msg.C_Error, &msg.Error{
+			Code:  "E00",
+			Items: "NOT_IMPLEMENTED",
+		})
+	}
+}
+
+func (s *Server) sendEncrypted(c *conn, env *msg.MessageEnvelope) error {
+	s.mtx.Lock()
+	authID, sessionID := c.authID, c.sessionID
+	authKey := s.authKeys[authID]
+	s.mtx.Unlock()
+	payload := &msg.ProtoEncryptedPayload{
+		ServerSalt: s.salt,
+		MessageID:  uint64(time.Now().Unix()<<32 | atomic.AddInt64(&s.messageSeq, 1)),
+		SessionID:  sessionID,
+		Envelope:   env,
+	}
+	plain, err := payload.Marshal()
+	if err != nil {
+		return err
+	}
+	// the message key goes first, Encrypt could seal the payload in place of plain
+	messageKey := utils.GenerateMessageKey(authKey, plain)
+	encrypted, err := utils.Encrypt(authKey, plain)
+	if err != nil {
+		return err
+	}
+	return s.send(c, &msg.ProtoMessage{
+		AuthID:     authID,
+		MessageKey: messageKey,
+		Payload:    encrypted,
+	})
+}
+
+func (s *Server) send(c *conn, pm *msg.ProtoMessage) error {
+	frame, err := pm.Marshal()
+	if err != nil {
+		return err
+	}
+	c.sendMtx.Lock()
+	defer c.sendMtx.Unlock()
+	return websocket.Message.Send(c.ws, frame)
+}
+
+// envelope marshals the message into an envelope of the constructor
+func envelope(constructor int64, m msg.Message) *msg.MessageEnvelope {
+	env := &msg.MessageEnvelope{Constructor: constructor}
+	env.Message, _ = m.Marshal()
+	return env
+}
+
+// authIDOf returns the auth id and the hash of the auth key, the same way the clients compute them
+func authIDOf(authKey []byte) (int64, []byte) {
+	h, _ := utils.Sha256(authKey)
+	return int64(binary.LittleEndian.Uint64(h[24:32])), h
+}
@@ -1,3 +1,16 @@
 #!/usr/bin/env bash
+set -e
 
-GOOS=js GOARCH=wasm tinygo build -o tiny.wasm -target wasm .
\ No newline at end of file
+# a wasm without the root key rejects every ServerKeys bundle, only the dev builds accept them unsigned
+if [ -z "${RIVER_ROOT_KEY}" ] && [ "${RIVER_DEV}" != "true" ]; then
+	echo "RIVER_ROOT_KEY is not set, set it or RIVER_DEV=true for a dev build" >&2
+	exit 1
+fi
+
+PKG=git.ronaksoft.com/river/web-wasm/connection
+LDFLAGS="-X ${PKG}.rootPublicKey=${RIVER_ROOT_KEY} -X ${PKG}.buildEnv=${RIVER_ENV:-prod} -X ${PKG}.devMode=${RIVER_DEV:-false}"
+OUT=${RIVER_WASM_OUT:-tiny.wasm}
+MAX_SIZE=${RIVER_MAX_TINY_WASM_SIZE:-3145728}
+
+GOOS=js GOARCH=wasm tinygo build -ldflags="${LDFLAGS}" -o "${OUT}" -target wasm .
+go run ./cmd/wasmsize -budget "${MAX_SIZE}" -strip "${OUT}"
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"math/big"
+)
+
+const (
+	// DHMinPrimeBits is the smallest size of the DH prime which we accept from the server
+	DHMinPrimeBits  = 2048
+	primalityRounds = 30
+)
+
+// CheckDHGroup checks that p is a safe prime of adequate size and g is a generator of
+// order q or 2q of the group. With a safe prime p = 2q + 1 the only other subgroups have
+// order 1 and 2, which are excluded by 1 < g < p-1.
+func CheckDHGroup(p, g *big.Int) error {
+	if p == nil || p.BitLen() < DHMinPrimeBits {
+		return _errors.ErrDHPrimeTooSmall
+	}
+	if p.Bit(0) == 0 || !p.ProbablyPrime(primalityRounds) {
+		return _errors.ErrInvalidDHPrime
+	}
+	q := big.NewInt(0).Rsh(p, 1)
+	if !q.ProbablyPrime(primalityRounds) {
+		return _errors.ErrInvalidDHPrime
+	}
+	if !inOpenRange(g, p) {
+		return _errors.ErrInvalidDHGenerator
+	}
+	return nil
+}
+
+// CheckDHPublicKey checks that 1 < y < p-1 for the public values g^a and g^b
+func CheckDHPublicKey(p, y *big.Int) error {
+	if !inOpenRange(y, p) {
+		return _errors.ErrInvalidDHPublicKey
+	}
+	return nil
+}
+
+// inOpenRange returns true if 1 < x < p-1
+func inOpenRange(x, p *big.Int) bool {
+	if x == nil || x.Cmp(big.NewInt(1)) <= 0 {
+		return false
+	}
+	pMinusOne := big.NewInt(0).Sub(p, big.NewInt(1))
+	return x.Cmp(pMinusOne) < 0
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"context"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"math/big"
+	"math/bits"
+	mathRand "math/rand"
+	"time"
+)
+
+const (
+	// DefaultSplitPQIterations is enough for any PQ of two 32 bits primes, Pollard-rho finds
+	// a factor after about sqrt(p) iterations
+	DefaultSplitPQIterations uint64 = 1 << 26
+	// splitPQBatch is the number of iterations between checking the context and reporting progress,
+	// it takes a few milliseconds in the browser
+	splitPQBatch = 1 << 16
+	// splitPQBlock is the number of steps which the gcd products are accumulated over
+	splitPQBlock = 128
+)
+
+// SplitPQProgress is called every splitPQBatch iterations with the iterations done so far, the wasm
+// thread is blocked by the factorization, so it is the place to yield to the event loop
+type SplitPQProgress func(iterations, maxIterations uint64)
+
+// SplitPQContext splits PQ to its two prime factors p < q. It stops when the context is done or
+// maxIterations (zero means DefaultSplitPQIterations) is exhausted. PQ is factorized by Brent's
+// variant of Pollard-rho on native integers, the server never sends a PQ wider than 64 bits, so
+// such a PQ is rejected rather than factorized without a budget.
+func SplitPQContext(ctx context.Context, pq *big.Int, maxIterations uint64, progress SplitPQProgress) (p, q *big.Int, err error) {
+	if pq.Sign() <= 0 || pq.Cmp(big.NewInt(3)) <= 0 {
+		return nil, nil, _errors.ErrInvalidPQ
+	}
+	if !pq.IsUint64() {
+		return nil, nil, _errors.ErrPQTooLarge
+	}
+	if pq.ProbablyPrime(20) {
+		return nil, nil, _errors.ErrInvalidPQ
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if maxIterations == 0 {
+		maxIterations = DefaultSplitPQIterations
+	}
+
+	p64, q64, err := splitPQ64(ctx, pq.Uint64(), maxIterations, progress)
+	if err != nil {
+		return nil, nil, err
+	}
+	return big.NewInt(0).SetUint64(p64), big.NewInt(0).SetUint64(q64), nil
+}
+
+// splitPQ64 is Brent's cycle detection over f(y) = y^2 + c mod n, with the gcd products
+// accumulated over blocks of m steps
+func splitPQ64(ctx context.Context, n uint64, maxIterations uint64, progress SplitPQProgress) (p, q uint64, err error) {
+	if n&1 == 0 {
+		return 2, n / 2, nil
+	}
+
+	const m = splitPQBlock
+	rnd := mathRand.New(mathRand.NewSource(time.Now().UnixNano()))
+	iterations := uint64(0)
+	nextCheck := uint64(splitPQBatch)
+	f := func(y, c uint64) uint64 {
+		return addMod64(mulMod64(y, y, n), c, n)
+	}
+	// spend counts the steps of a block, the budget is checked after every block, so it holds even if it
+	// is less than a batch, and the context after every batch
+	spend := func(steps uint64, found bool) error {
+		iterations += steps
+		if !found && iterations >= maxIterations {
+			return _errors.ErrSplitPQBudget
+		}
+		if iterations >= nextCheck {
+			nextCheck = iterations + splitPQBatch
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if progress != nil {
+				progress(iterations, maxIterations)
+			}
+		}
+		return nil
+	}
+
+	for {
+		y := rnd.Uint64()%(n-1) + 1
+		c := rnd.Uint64()%(n-1) + 1
+		g, r, acc := uint64(1), uint64(1), uint64(1)
+		var x, ys uint64
+
+		for g == 1 {
+			x = y
+			// the steps which are skipped before the products are accumulated are spent in blocks too
+			for k := uint64(0); k < r; k += m {
+				steps := r - k
+				if steps > m {
+					steps = m
+				}
+				for i := uint64(0); i < steps; i++ {
+					y = f(y, c)
+				}
+				if err = spend(steps, false); err != nil {
+					return
+				}
+			}
+			for k := uint64(0); k < r && g == 1; k += m {
+				ys = y
+				steps := r - k
+				if steps > m {
+					steps = m
+				}
+				for i := uint64(0); i < steps; i++ {
+					y = f(y, c)
+					acc = mulMod64(acc, absDiff64(x, y), n)
+				}
+				g = gcd64(acc, n)
+				if err = spend(steps, g != 1); err != nil {
+					return
+				}
+			}
+			r <<= 1
+		}
+
+		// the block overshot, backtrack step by step from its start
+		if g == n {
+			for {
+				ys = f(ys, c)
+				g = gcd64(absDiff64(x, ys), n)
+				if g > 1 {
+					break
+				}
+			}
+		}
+
+		if g != n {
+			p, q = g, n/g
+			if p > q {
+				p, q = q, p
+			}
+			return
+		}
+		// the cycle closed without finding a factor, retry with another constant
+	}
+}
+
+func mulMod64(a, b, n uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, n)
+}
+
+func addMod64(a, b, n uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	return bits.Rem64(carry, sum, n)
+}
+
+func absDiff64(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func gcd64(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+// testPQs are products of two 31 bits primes, as the server sends them
+var testPQs = []struct {
+	pq, p, q uint64
+}{
+	{1724114033281923457, 1229739323, 1402015859},
+	{4611685975477714963, 2147483629, 2147483647},
+	{15, 3, 5},
+	{2 * 2147483647, 2, 2147483647},
+}
+
+func TestSplitPQContext(t *testing.T) {
+	for _, tt := range testPQs {
+		p, q, err := SplitPQContext(context.Background(), big.NewInt(0).SetUint64(tt.pq), 0, nil)
+		if err != nil {
+			t.Fatalf("%d: %v", tt.pq, err)
+		}
+		if p.Uint64() != tt.p || q.Uint64() != tt.q {
+			t.Fatalf("%d split to %v * %v, expected %d * %d", tt.pq, p, q, tt.p, tt.q)
+		}
+	}
+}
+
+func TestSplitPQContextErrors(t *testing.T) {
+	wide, _ := big.NewInt(0).SetString("340282366920938463463374607431768211457", 10)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		pq            *big.Int
+		maxIterations uint64
+		err           error
+	}{
+		{"zero", context.Background(), big.NewInt(0), 0, _errors.ErrInvalidPQ},
+		{"three", context.Background(), big.NewInt(3), 0, _errors.ErrInvalidPQ},
+		{"prime", context.Background(), big.NewInt(2147483647), 0, _errors.ErrInvalidPQ},
+		{"wide", context.Background(), wide, 0, _errors.ErrPQTooLarge},
+		{"budget", context.Background(), big.NewInt(0).SetUint64(testPQs[1].pq), 1, _errors.ErrSplitPQBudget},
+		{"cancelled", cancelled, big.NewInt(0).SetUint64(testPQs[1].pq), 0, context.Canceled},
+	}
+	for _, tt := range tests {
+		if _, _, err := SplitPQContext(tt.ctx, tt.pq, tt.maxIterations, nil); err != tt.err {
+			t.Errorf("%s: %v, expected %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+// TestSplitPQChecks splits a product of two 32 bits primes, which takes more than a batch of steps, the
+// context must be checked and the progress reported at least once every batch and block
+func TestSplitPQChecks(t *testing.T) {
+	const pq = 4294967291 * 4294967279
+	for run := 0; run < 16; run++ {
+		var last uint64
+		p, q, err := splitPQ64(context.Background(), pq, DefaultSplitPQIterations, func(iterations, _ uint64) {
+			if iterations-last > splitPQBatch+splitPQBlock {
+				t.Fatalf("%d steps are run between the checks", iterations-last)
+			}
+			last = iterations
+		})
+		if err != nil || p*q != pq {
+			t.Fatalf("split to %d * %d: %v", p, q, err)
+		}
+	}
+}
+
+// BenchmarkSplitPQ compares Brent's variant on uint64 with the big.Int implementation of SplitPQ
+func BenchmarkSplitPQ(b *testing.B) {
+	pq := big.NewInt(0).SetUint64(testPQs[1].pq)
+	b.Run("Brent64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := SplitPQContext(context.Background(), pq, 0, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("BigInt", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SplitPQ(pq)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"math/big"
+)
+
+const (
+	// SrpPrimeBits is the exact size of the SRP prime
+	SrpPrimeBits = bitSize
+	// srpPublicMargin the public values must be at least 2^(SrpPrimeBits-64) away from 0 and p
+	srpPublicMargin = SrpPrimeBits - 64
+)
+
+// CheckSrpGroup checks that p is a 2048 bits safe prime p = 2q + 1 and g generates the subgroup
+// of order q. Since q is prime, any quadratic residue other than 1 generates it, otherwise
+// g^x would leak the parity of x which is derived from the password.
+func CheckSrpGroup(p, g *big.Int) error {
+	if p == nil || p.BitLen() != SrpPrimeBits || p.Bit(0) == 0 {
+		return _errors.ErrInvalidSrpPrime
+	}
+	if !inOpenRange(g, p) || big.Jacobi(g, p) != 1 {
+		return _errors.ErrInvalidSrpGenerator
+	}
+	if !p.ProbablyPrime(primalityRounds) {
+		return _errors.ErrInvalidSrpPrime
+	}
+	q := big.NewInt(0).Rsh(p, 1)
+	if !q.ProbablyPrime(primalityRounds) {
+		return _errors.ErrInvalidSrpPrime
+	}
+	return nil
+}
+
+// CheckSrpPublic checks the public values A and B are in (2^1984, p - 2^1984), which rules out
+// B mod p == 0 and the small values which the shared secret could be guessed from
+func CheckSrpPublic(p, x *big.Int) bool {
+	if x == nil || !inOpenRange(x, p) {
+		return false
+	}
+	margin := big.NewInt(0).Lsh(big.NewInt(1), srpPublicMargin)
+	if x.Cmp(margin) <= 0 {
+		return false
+	}
+	return x.Cmp(big.NewInt(0).Sub(p, margin)) < 0
+}
+
+// M2 is the proof of the server, M2 = H(A, M1, H(S)). The client checks it to be sure the server knows
+// the verifier, since a server without the verifier could not compute S.
+func M2(ga *big.Int, m1 []byte, sb *big.Int) []byte {
+	return H(Pad(ga), m1, H(Pad(sb)))
+}
+
+// SrpServer
+// The server side of SRP-6a, it is used for a single password check. It is meant for the tests and
+// the local server stubs.
+type SrpServer struct {
+	p, g, v      *big.Int
+	salt1, salt2 []byte
+	b, gb        *big.Int
+}
+
+// NewSrpServer validates the group and generates B = k*v + g^b from the verifier v = g^x
+func NewSrpServer(p, g *big.Int, salt1, salt2, verifier []byte) (*SrpServer, error) {
+	if err := CheckSrpGroup(p, g); err != nil {
+		return nil, err
+	}
+	s := &SrpServer{
+		p:     p,
+		g:     g,
+		v:     big.NewInt(0).SetBytes(verifier),
+		salt1: salt1,
+		salt2: salt2,
+	}
+	if !inOpenRange(s.v, p) {
+		return nil, _errors.ErrInvalidSrpVerifier
+	}
+
+	k := big.NewInt(0).SetBytes(K(p, g))
+	kv := big.NewInt(0).Mul(k, s.v)
+	for s.gb == nil || !CheckSrpPublic(p, s.gb) {
+		random := make([]byte, SrpByteSize)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		s.b = big.NewInt(0).SetBytes(random)
+		s.gb = big.NewInt(0).Exp(g, s.b, p)
+		s.gb.Add(s.gb, kv).Mod(s.gb, p)
+	}
+	return s, nil
+}
+
+// B returns the public value of the server padded to SrpByteSize, it is sent in AccountPassword.SrpB
+func (s *SrpServer) B() []byte {
+	return Pad(s.gb)
+}
+
+// Verify checks the proof M1 of the client by its public value A and returns the proof M2 of the server
+func (s *SrpServer) Verify(a, m1 []byte) (m2 []byte, err error) {
+	ga := big.NewInt(0).SetBytes(a)
+	if !CheckSrpPublic(s.p, ga) {
+		return nil, _errors.ErrInvalidSrpA
+	}
+	u := big.NewInt(0).SetBytes(U(ga, s.gb))
+	if u.Sign() == 0 {
+		return nil, _errors.ErrInvalidSrpU
+	}
+
+	// S = (A * v^u) ^ b
+	sb := big.NewInt(0).Exp(s.v, u, s.p)
+	sb.Mul(sb, ga).Mod(sb, s.p)
+	sb.Exp(sb, s.b, s.p)
+
+	expected := M(s.p, s.g, s.salt1, s.salt2, ga, s.gb, sb)
+	if subtle.ConstantTimeCompare(expected, m1) != 1 {
+		return nil, _errors.ErrInvalidSrpM1
+	}
+	return M2(ga, m1, sb), nil
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/cipher"
 	"crypto/sha256"
 	"crypto/sha512"
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+	"golang.org/x/crypto/argon2"
 	"math/big"
 	mathRand "math/rand"
 	"time"
@@ -272,6 +274,12 @@ func PH2(password, salt1, salt2 []byte) []byte {
 	return SH(SH(PH1(password, salt1, salt2), salt1), salt2)
 }
 
+// PH2Argon2id is PH2 of the memory-hard password algorithm, PH1 is stretched by Argon2id so
+// brute forcing the verifier needs as much memory as the client
+func PH2Argon2id(password, salt1, salt2 []byte, iterations, memory uint32, parallelism uint8) []byte {
+	return SH(argon2.IDKey(PH1(password, salt1, salt2), salt1, iterations, memory, parallelism, 64), salt2)
+}
+
 func K(p, g *big.Int) []byte {
 	return H(append(Pad(p), Pad(g)...))
 }
@@ -284,6 +292,14 @@ func M(p, g *big.Int, s1, s2 []byte, ga, gb, sb *big.Int) []byte {
 	return H(H(Pad(p)), H(Pad(g)), H(s1), H(s2), H(Pad(ga)), H(Pad(gb)), H(Pad(sb)))
 }
 
+// FixedBytes returns the big-endian encoding of x left padded to n bytes
+func FixedBytes(x *big.Int, n int) ([]byte, error) {
+	if x.Sign() < 0 || (x.BitLen()+7)/8 > n {
+		return nil, _errors.ErrValueTooLarge
+	}
+	return x.FillBytes(make([]byte, n)), nil
+}
+
 // Pad x to n bytes if needed
 func Pad(x *big.Int) []byte {
 	b := x.Bytes()
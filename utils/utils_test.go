@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	_errors "git.ronaksoft.com/river/web-wasm/errors"
+)
+
+func TestFixedBytes(t *testing.T) {
+	pow := func(n uint) *big.Int {
+		return big.NewInt(0).Lsh(big.NewInt(1), n)
+	}
+	tests := []struct {
+		name string
+		x    *big.Int
+		// zeros is the number of the leading zero bytes, first is the byte after them
+		zeros int
+		first byte
+	}{
+		{"2^2047", pow(2047), 0, 0x80},
+		{"2^2039", pow(2039), 1, 0x80},
+		{"2^2038", pow(2038), 1, 0x40},
+		{"2^2031+1", big.NewInt(0).Add(pow(2031), big.NewInt(1)), 2, 0x80},
+		{"1", big.NewInt(1), 255, 0x01},
+	}
+	for _, tt := range tests {
+		b, err := FixedBytes(tt.x, 256)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(b) != 256 {
+			t.Fatalf("%s: %d bytes", tt.name, len(b))
+		}
+		if !bytes.Equal(b[:tt.zeros], make([]byte, tt.zeros)) || b[tt.zeros] != tt.first {
+			t.Fatalf("%s: %x", tt.name, b[:tt.zeros+1])
+		}
+		if !bytes.Equal(b[tt.zeros:], tt.x.Bytes()) {
+			t.Fatalf("%s: the bytes after the padding are not x", tt.name)
+		}
+	}
+
+	if b, err := FixedBytes(big.NewInt(0), 256); err != nil || !bytes.Equal(b, make([]byte, 256)) {
+		t.Fatalf("zero: %x %v", b, err)
+	}
+	if _, err := FixedBytes(pow(2048), 256); err != _errors.ErrValueTooLarge {
+		t.Fatalf("2^2048: %v, expected %v", err, _errors.ErrValueTooLarge)
+	}
+	if _, err := FixedBytes(big.NewInt(-1), 256); err != _errors.ErrValueTooLarge {
+		t.Fatalf("-1: %v, expected %v", err, _errors.ErrValueTooLarge)
+	}
+}